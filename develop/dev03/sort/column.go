@@ -1,15 +1,12 @@
 package sort
 
-import (
-	"slices"
-	"strings"
-)
+import "strings"
 
-// Получение значения колонки (вспомогательная функция для OnlyUnique())
-func GetColumnValue(text []*StrokeEntry, i, column int, ignoreTrailingBlanks bool) string {
+// Получение значения колонки (пустая строка, если колонки нет)
+func GetColumnValue(entry *StrokeEntry, column int, ignoreTrailingBlanks bool) string {
 	value := ""
-	if len(text[i].Content) > column {
-		value = text[i].Content[column]
+	if len(entry.Content) > column {
+		value = entry.Content[column]
 	}
 	if ignoreTrailingBlanks {
 		value = strings.TrimRight(value, " ")
@@ -17,31 +14,33 @@ func GetColumnValue(text []*StrokeEntry, i, column int, ignoreTrailingBlanks boo
 	return value
 }
 
-// сортировка по колонке
-func ColumnSort(text []*StrokeEntry, column int, ignoreTrailingBlanks bool) {
-	slices.SortFunc(text, func(a, b *StrokeEntry) int {
-		valueA := ""
-		if len(a.Content) > column {
-			valueA = a.Content[column]
-		}
-		if ignoreTrailingBlanks {
-			valueA = strings.TrimRight(valueA, " ")
-		}
-
-		valueB := ""
-		if len(b.Content) > column {
-			valueB = b.Content[column]
-		}
-		if ignoreTrailingBlanks {
-			valueB = strings.TrimRight(valueB, " ")
-		}
+// Получение значения, по которому сравниваются строки (если wholeLine - строка целиком, иначе значение колонки)
+func GetKeyValue(entry *StrokeEntry, column int, wholeLine, ignoreTrailingBlanks bool) string {
+	if !wholeLine {
+		return GetColumnValue(entry, column, ignoreTrailingBlanks)
+	}
+	if ignoreTrailingBlanks {
+		return strings.TrimRight(entry.Stroke, " ")
+	}
+	return entry.Stroke
+}
 
-		if valueA < valueB {
-			return -1
+// Получение границ (в байтах) значения, по которому сравниваются строки, внутри entry.Stroke
+// (ok == false, если колонки в строке нет)
+func GetKeySpan(entry *StrokeEntry, column int, wholeLine, ignoreTrailingBlanks bool) (start, end int, ok bool) {
+	value := entry.Stroke
+	if !wholeLine {
+		if len(entry.Content) <= column {
+			return 0, 0, false
 		}
-		if valueA > valueB {
-			return 1
+		// Колонки идут подряд и разделены одним пробельным символом (см. Split())
+		for _, word := range entry.Content[:column] {
+			start += len(word) + 1
 		}
-		return 0
-	})
+		value = entry.Content[column]
+	}
+	if ignoreTrailingBlanks {
+		value = strings.TrimRight(value, " ")
+	}
+	return start, start + len(value), true
 }
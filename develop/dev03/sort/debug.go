@@ -0,0 +1,78 @@
+package sort
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Получение строки-аннотации для режима -debug: под значением, по которому сравнивается строка, ставится подчёркивание
+// (как в GNU sort --debug)
+func KeyAnnotation(entry *StrokeEntry, options Options) string {
+	wholeLine := options.ComparatorName() == DefaultComparator && options.Column == 0
+	start, end, ok := GetKeySpan(entry, options.Column, wholeLine, options.IgnoreTrailingBlanks)
+	if options.ComparatorName() == "time" {
+		_, start, end, ok = GetTimeKeySpan(entry, options.Column, options.IgnoreTrailingBlanks)
+	}
+	if !ok {
+		return indent(entry.Stroke) + "^ no match for key"
+	}
+	if start == end {
+		return indent(entry.Stroke[:start]) + "^ no match for key"
+	}
+	return indent(entry.Stroke[:start]) + strings.Repeat("_", utf8.RuneCountInString(entry.Stroke[start:end]))
+}
+
+// Отступ той же ширины, что и prefix (табуляции сохраняются для выравнивания)
+func indent(prefix string) string {
+	var builder strings.Builder
+	for _, char := range prefix {
+		if char == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	return builder.String()
+}
+
+// Получение предупреждений о сомнительных сочетаниях флагов для режима -debug
+func DebugWarnings(options Options) []string {
+	warnings := []string{}
+	mode := options.ComparatorName()
+
+	// Указано несколько режимов сортировки - используется только один
+	modes := []string{}
+	if options.SortMode != "" {
+		modes = append(modes, "-sort "+options.SortMode)
+	}
+	if options.Numeric {
+		modes = append(modes, "-n")
+	}
+	if options.MonthSort {
+		modes = append(modes, "-M")
+	}
+	if options.NumericSuffixes {
+		modes = append(modes, "-h")
+	}
+	if len(modes) > 1 {
+		warnings = append(warnings, fmt.Sprintf("options '%s' are incompatible; only '%s' is used", strings.Join(modes, "' '"), modes[0]))
+	}
+
+	if options.IgnoreTrailingBlanks && !options.ColumnGiven {
+		warnings = append(warnings, "option '-b' is used without '-k'; trailing blanks are ignored for the whole key")
+	}
+	if options.TimeLayout != "" && mode != "time" {
+		warnings = append(warnings, fmt.Sprintf("option '-time-layout' is ignored in '%s' sort mode", mode))
+	}
+	if options.CheckIfSorted && options.Unique {
+		warnings = append(warnings, "option '-u' with '-c': lines with repeated keys are reported as not sorted")
+	}
+
+	if mode == DefaultComparator {
+		warnings = append(warnings, "text ordering performed using simple byte comparison")
+	} else {
+		warnings = append(warnings, fmt.Sprintf("ordering performed using '%s' sort mode", mode))
+	}
+	return warnings
+}
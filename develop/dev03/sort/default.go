@@ -1,27 +1,14 @@
 package sort
 
-import (
-	"slices"
-	"strings"
-)
+import "strings"
 
-// Получение строки по индексу (вспомогательная функция для OnlyUnique())
-func GetDefaultValue(text []*StrokeEntry, i, column int, ignoreTrailingBlanks bool) string {
-	if ignoreTrailingBlanks {
-		return strings.TrimRight(text[i].Stroke, " ")
-	}
-	return text[i].Stroke
+// Лексикографическое сравнение строк
+type LexicalComparator struct{}
+
+func (LexicalComparator) Compare(a, b string) int {
+	return strings.Compare(a, b)
 }
 
-// Сортировка по строкам
-func DefaultSort(text []*StrokeEntry, column int, ignoreTrailingBlanks bool) {
-	slices.SortFunc(text, func(a, b *StrokeEntry) int {
-		if a.Stroke < b.Stroke {
-			return -1
-		}
-		if a.Stroke > b.Stroke {
-			return 1
-		}
-		return 0
-	})
+func (LexicalComparator) Key(value string) string {
+	return value
 }
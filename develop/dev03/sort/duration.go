@@ -0,0 +1,27 @@
+package sort
+
+import (
+	"cmp"
+	"time"
+)
+
+// Сравнение по продолжительности в формате Go ("1h30m", "250ms", "-1.5s"). Значения, не являющиеся продолжительностью,
+// идут первыми
+type DurationComparator struct{}
+
+func (DurationComparator) Compare(a, b string) int {
+	durationA, errA := time.ParseDuration(a)
+	durationB, errB := time.ParseDuration(b)
+	if errA != nil || errB != nil {
+		return compareValidity(errA == nil, errB == nil)
+	}
+	return cmp.Compare(durationA, durationB)
+}
+
+// Одинаковые продолжительности в разной записи ("90m" и "1h30m") считаются одинаковыми
+func (DurationComparator) Key(value string) string {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration.String()
+	}
+	return ""
+}
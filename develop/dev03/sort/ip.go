@@ -0,0 +1,61 @@
+package sort
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// Сравнение по IP-адресу (IPv4 и IPv6, в том числе в CIDR-нотации). Адреса IPv4 идут перед IPv6, при равных адресах
+// сеть с меньшей длиной префикса идёт первой. Значения, не являющиеся адресом, идут первыми
+type IPComparator struct{}
+
+// Разбор IP-адреса или подсети (адрес без префикса считается подсетью из одного адреса)
+func ParseIPPrefix(value string) (netip.Prefix, bool) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		// IPv4-адреса, отображённые в IPv6 (::ffff:a.b.c.d), сравниваются как IPv4
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix, true
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+func (IPComparator) Compare(a, b string) int {
+	prefixA, okA := ParseIPPrefix(a)
+	prefixB, okB := ParseIPPrefix(b)
+	if !okA || !okB {
+		return compareValidity(okA, okB)
+	}
+	if result := prefixA.Addr().Compare(prefixB.Addr()); result != 0 {
+		return result
+	}
+	return prefixA.Bits() - prefixB.Bits()
+}
+
+func (IPComparator) Key(value string) string {
+	if prefix, ok := ParseIPPrefix(value); ok {
+		return prefix.String()
+	}
+	return ""
+}
+
+// Сравнение значений, хотя бы одно из которых не удалось разобрать (неразобранные значения идут первыми и равны между собой)
+func compareValidity(okA, okB bool) int {
+	switch {
+	case okA == okB:
+		return 0
+	case okA:
+		return 1
+	}
+	return -1
+}
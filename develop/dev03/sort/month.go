@@ -1,10 +1,6 @@
 package sort
 
-import (
-	"slices"
-	"strconv"
-	"strings"
-)
+import "strconv"
 
 // Порядок месяцев
 var MonthsOrder map[string]int = map[string]int{
@@ -34,43 +30,17 @@ var MonthsOrder map[string]int = map[string]int{
 	"December":  122,
 }
 
-// Получение месяца по индексу строки и колонке (вспомогательная функция для OnlyUnique())
-func GetMonthValue(text []*StrokeEntry, i, column int, ignoreTrailingBlanks bool) string {
-	if len(text[i].Content) > column {
-		key := text[i].Content[column]
-		if ignoreTrailingBlanks {
-			key = strings.TrimRight(key, " ")
-		}
-		if val, ok := MonthsOrder[key]; ok {
-			return strconv.Itoa(val / 10)
-		}
-	}
-	return ""
+// Сравнение по названию месяца (значения, не являющиеся месяцем, идут первыми)
+type MonthComparator struct{}
+
+func (MonthComparator) Compare(a, b string) int {
+	return MonthsOrder[a] - MonthsOrder[b]
 }
 
-// Сортировка по месяцу
-func MonthSort(text []*StrokeEntry, column int, ignoreTrailingBlanks bool) {
-	slices.SortFunc(text, func(a, b *StrokeEntry) int {
-		valueA := 0
-		if len(a.Content) > column {
-			key := a.Content[column]
-			if ignoreTrailingBlanks {
-				key = strings.TrimSuffix(key, " ")
-			}
-			if val, ok := MonthsOrder[key]; ok {
-				valueA = val
-			}
-		}
-		valueB := 0
-		if len(b.Content) > column {
-			key := b.Content[column]
-			if ignoreTrailingBlanks {
-				key = strings.TrimSuffix(key, " ")
-			}
-			if val, ok := MonthsOrder[key]; ok {
-				valueB = val
-			}
-		}
-		return valueA - valueB
-	})
+// Полное и сокращённое название одного месяца считаются одинаковыми ("Jan" и "January")
+func (MonthComparator) Key(value string) string {
+	if order, ok := MonthsOrder[value]; ok {
+		return strconv.Itoa(order / 10)
+	}
+	return ""
 }
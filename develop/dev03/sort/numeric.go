@@ -1,44 +1,20 @@
 package sort
 
-import (
-	"slices"
-	"strconv"
-	"strings"
-)
+import "strconv"
 
-// Получение числа по индексу строки и колонке (вспомогательная функция для OnlyUnique())
-func GetNumericValue(text []*StrokeEntry, i, column int, ignoreTrailingBlanks bool) string {
-	if len(text[i].Content) > column {
-		word := text[i].Content[column]
-		if ignoreTrailingBlanks {
-			word = strings.TrimRight(word, " ")
-		}
-		if value, err := strconv.Atoi(word); err == nil {
-			return strconv.Itoa(value)
-		}
-	}
-	return ""
+// Сравнение по числовому значению (значения, не являющиеся числом, считаются равными 0)
+type NumericComparator struct{}
+
+func (NumericComparator) Compare(a, b string) int {
+	valueA, _ := strconv.Atoi(a)
+	valueB, _ := strconv.Atoi(b)
+	return valueA - valueB
 }
 
-// Сортировка по числу
-func NumericSort(text []*StrokeEntry, column int, ignoreTrailingBlanks bool) {
-	slices.SortFunc(text, func(a, b *StrokeEntry) int {
-		valueA := 0
-		if len(a.Content) > column {
-			val := a.Content[column]
-			if ignoreTrailingBlanks {
-				val = strings.TrimSuffix(val, " ")
-			}
-			valueA, _ = strconv.Atoi(val)
-		}
-		valueB := 0
-		if len(b.Content) > column {
-			val := b.Content[column]
-			if ignoreTrailingBlanks {
-				val = strings.TrimSuffix(val, " ")
-			}
-			valueB, _ = strconv.Atoi(val)
-		}
-		return valueA - valueB
-	})
+// Числа с ведущими нулями считаются одинаковыми ("07" и "7"), все не числа - одним значением
+func (NumericComparator) Key(value string) string {
+	if number, err := strconv.Atoi(value); err == nil {
+		return strconv.Itoa(number)
+	}
+	return ""
 }
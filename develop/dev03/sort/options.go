@@ -3,10 +3,14 @@ package sort
 import (
 	"errors"
 	"flag"
+	"strings"
 )
 
 var ErrNonPositiveColumn error = errors.New("column must be a positive number")
 var ErrNotEnoughArguments error = errors.New("not enough arguments")
+var ErrNegativeLimit error = errors.New("-top and -bottom must be non-negative numbers")
+var ErrIncompatibleLimit error = errors.New("-top and -bottom can't be used together or with -c")
+var ErrCheckWithOutput error = errors.New("-o can't be used with -c")
 
 type Options struct {
 	Filepath             string
@@ -18,6 +22,19 @@ type Options struct {
 	Unique               bool
 	IgnoreTrailingBlanks bool
 	CheckIfSorted        bool
+	Output               string
+	ZeroTerminated       bool
+	KeepTerminators      bool
+	// Имя зарегистрированного режима сортировки (если пусто - определяется флагами -n, -M, -h)
+	SortMode string
+	// Формат даты и времени для режима "time" (если пусто - DefaultTimeLayouts)
+	TimeLayout string
+	Debug      bool
+	// Колонка указана явно флагом -k (Column == 0 также соответствует -k 1)
+	ColumnGiven bool
+	// Вывод только первых (Top) или последних (Bottom) N строк отсортированного текста (0 - все строки)
+	Top    int
+	Bottom int
 }
 
 func NewOptions(filepath string, column int, numeric, monthSort, numericSuffixes, reversed, unique, ignoreTrailingBlanks, checkIfSorted bool) Options {
@@ -34,6 +51,32 @@ func NewOptions(filepath string, column int, numeric, monthSort, numericSuffixes
 	}
 }
 
+// Получение имени режима сортировки
+func (options Options) ComparatorName() string {
+	switch {
+	case options.SortMode != "":
+		return options.SortMode
+	case options.TimeLayout != "":
+		return "time"
+	case options.Numeric:
+		return "numeric"
+	case options.MonthSort:
+		return "month"
+	case options.NumericSuffixes:
+		return "human-numeric"
+	}
+	return DefaultComparator
+}
+
+// Получение компаратора для режима сортировки (для режима "time" учитывается формат из -time-layout)
+func (options Options) Comparator() (KeyComparator, error) {
+	name := options.ComparatorName()
+	if name == "time" && options.TimeLayout != "" {
+		return NewTimeComparator(options.TimeLayout), nil
+	}
+	return LookupComparator(name)
+}
+
 // Получение значений флагов и аргументов
 func ParseArguments(arguments []string) (Options, error) {
 	fSet := flag.NewFlagSet("sort", flag.ContinueOnError)
@@ -45,6 +88,14 @@ func ParseArguments(arguments []string) (Options, error) {
 	ignoreTrailingBlanks := fSet.Bool("b", false, "ignore trailing spaces")
 	checkIfSorted := fSet.Bool("c", false, "check if data is sorted")
 	numericSuffixes := fSet.Bool("h", false, "sort by numeric value taking into account suffixes")
+	output := fSet.String("o", "", "write result to file instead of standard output (file may be the same as input)")
+	zeroTerminated := fSet.Bool("z", false, "line delimiter is NUL, not newline")
+	keepTerminators := fSet.Bool("keep-endings", false, "preserve original line endings (e.g. \"\\r\\n\") in output")
+	sortMode := fSet.String("sort", "", "sort according to registered mode: "+strings.Join(ComparatorNames(), ", "))
+	debug := fSet.Bool("debug", false, "annotate the part of each line used for sorting, warn about questionable usage to stderr")
+	top := fSet.Int("top", 0, "output only first N lines of sorted text (without sorting everything)")
+	bottom := fSet.Int("bottom", 0, "output only last N lines of sorted text (without sorting everything)")
+	timeLayout := fSet.String("time-layout", "", "date and time layout in Go notation for \"time\" sort mode (e.g. \"02.01.2006\")")
 	if err := fSet.Parse(arguments); err != nil {
 		return Options{}, err
 	}
@@ -57,5 +108,37 @@ func ParseArguments(arguments []string) (Options, error) {
 	if *column < 1 {
 		return Options{}, ErrNonPositiveColumn
 	}
-	return NewOptions(filepath, *column-1, *numeric, *monthSort, *numericSuffixes, *reversed, *unique, *ignoreTrailingBlanks, *checkIfSorted), nil
+
+	if *top < 0 || *bottom < 0 {
+		return Options{}, ErrNegativeLimit
+	}
+	if (*top > 0 && *bottom > 0) || ((*top > 0 || *bottom > 0) && *checkIfSorted) {
+		return Options{}, ErrIncompatibleLimit
+	}
+
+	// Результат проверки не должен заменять файл (в том числе входной) - как и в GNU sort, это ошибка
+	if *checkIfSorted && *output != "" {
+		return Options{}, ErrCheckWithOutput
+	}
+
+	if *sortMode != "" {
+		if _, err := LookupComparator(*sortMode); err != nil {
+			return Options{}, err
+		}
+	}
+	options := NewOptions(filepath, *column-1, *numeric, *monthSort, *numericSuffixes, *reversed, *unique, *ignoreTrailingBlanks, *checkIfSorted)
+	options.Output = *output
+	options.ZeroTerminated = *zeroTerminated
+	options.KeepTerminators = *keepTerminators
+	options.SortMode = *sortMode
+	options.TimeLayout = *timeLayout
+	options.Debug = *debug
+	fSet.Visit(func(f *flag.Flag) {
+		if f.Name == "k" {
+			options.ColumnGiven = true
+		}
+	})
+	options.Top = *top
+	options.Bottom = *bottom
+	return options, nil
 }
@@ -0,0 +1,70 @@
+package sort
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Права доступа для выходного файла, если он ещё не существует
+const defaultOutputMode os.FileMode = 0644
+
+// Атомарная запись в файл: данные записываются во временный файл в той же директории, после чего
+// временный файл переименовывается поверх целевого (права доступа и владелец целевого файла сохраняются)
+func WriteFileAtomic(path string, write func(io.Writer) error) error {
+	// Если путь - символическая ссылка, заменяется файл, на который она указывает
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	mode := defaultOutputMode
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		mode = info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".sort-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	// Удаление временного файла в случае ошибки
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			temp.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	if err := write(temp); err != nil {
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		return err
+	}
+	if err := temp.Chmod(mode); err != nil {
+		return err
+	}
+	if info != nil {
+		preserveOwner(temp, info)
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return err
+	}
+	succeeded = true
+	return nil
+}
+
+// Сортировка текста с записью результата в файл (файл может совпадать с входным)
+func SortToFile(in io.Reader, path string, options Options) error {
+	return WriteFileAtomic(path, func(out io.Writer) error {
+		return Sort(in, out, options)
+	})
+}
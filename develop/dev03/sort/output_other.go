@@ -0,0 +1,8 @@
+//go:build !unix
+
+package sort
+
+import "os"
+
+// На системах, отличных от unix, владелец файла не переносится
+func preserveOwner(file *os.File, info os.FileInfo) {}
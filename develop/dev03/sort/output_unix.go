@@ -0,0 +1,21 @@
+//go:build unix
+
+package sort
+
+import (
+	"os"
+	"syscall"
+)
+
+// Установка временному файлу владельца целевого файла (без прав суперпользователя смена владельца
+// может быть невозможна - в этом случае ошибка игнорируется, как и в GNU coreutils)
+func preserveOwner(file *os.File, info os.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	if stat.Uid == uint32(os.Getuid()) && stat.Gid == uint32(os.Getgid()) {
+		return
+	}
+	_ = file.Chown(int(stat.Uid), int(stat.Gid))
+}
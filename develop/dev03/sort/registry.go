@@ -0,0 +1,100 @@
+package sort
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var ErrUnknownComparator error = errors.New("unknown sort mode")
+var ErrComparatorExists error = errors.New("sort mode is already registered")
+var ErrInvalidComparator error = errors.New("sort mode must have a name and a comparator")
+
+// Название режима сортировки по умолчанию (лексикографическое сравнение)
+const DefaultComparator = "default"
+
+// Способ сравнения значений, по которым сортируются строки (ключей)
+type KeyComparator interface {
+	// Сравнение двух ключей: отрицательное число, если a < b, 0 - если a == b, положительное число, если a > b
+	Compare(a, b string) int
+	// Нормализованное значение ключа для OnlyUnique() (строки с одинаковым значением считаются повторяющимися)
+	Key(value string) string
+}
+
+// Реализация KeyComparator на основе функций (KeyFunc может быть nil - тогда ключ не нормализуется)
+type ComparatorFuncs struct {
+	CompareFunc func(a, b string) int
+	KeyFunc     func(value string) string
+}
+
+func (c ComparatorFuncs) Compare(a, b string) int {
+	return c.CompareFunc(a, b)
+}
+
+func (c ComparatorFuncs) Key(value string) string {
+	if c.KeyFunc == nil {
+		return value
+	}
+	return c.KeyFunc(value)
+}
+
+// Реестр режимов сортировки
+var registry = struct {
+	sync.RWMutex
+	comparators map[string]KeyComparator
+}{
+	comparators: map[string]KeyComparator{
+		DefaultComparator: LexicalComparator{},
+		"numeric":         NumericComparator{},
+		"month":           MonthComparator{},
+		"human-numeric":   NumericSuffixesComparator{},
+		"ip":              IPComparator{},
+		"time":            NewTimeComparator(),
+		"duration":        DurationComparator{},
+	},
+}
+
+// Регистрация нового режима сортировки (после регистрации режим доступен по имени, в том числе через флаг -sort)
+func RegisterComparator(name string, comparator KeyComparator) error {
+	if name == "" || comparator == nil {
+		return ErrInvalidComparator
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.comparators[name]; ok {
+		return fmt.Errorf("%w: %s", ErrComparatorExists, name)
+	}
+	registry.comparators[name] = comparator
+	return nil
+}
+
+// Удаление режима сортировки из реестра (используется в тестах, чтобы не оставлять в реестре тестовые режимы)
+func unregisterComparator(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.comparators, name)
+}
+
+// Получение режима сортировки по имени
+func LookupComparator(name string) (KeyComparator, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	comparator, ok := registry.comparators[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownComparator, name)
+	}
+	return comparator, nil
+}
+
+// Получение отсортированного списка имён зарегистрированных режимов сортировки
+func ComparatorNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.comparators))
+	for name := range registry.comparators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
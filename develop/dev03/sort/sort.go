@@ -3,6 +3,7 @@ package sort
 import (
 	"bufio"
 	"io"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -11,6 +12,8 @@ type StrokeEntry struct {
 	Content      []string
 	Stroke       string
 	InitialIndex int
+	// Исходный разделитель записи ("\n", "\r\n", "\x00" или "" у последней записи без разделителя)
+	Terminator string
 }
 
 // Удаление специальных символов в конце строки ("\n" или "\r\n")
@@ -18,28 +21,80 @@ func trimString(str string) string {
 	return strings.TrimSuffix(strings.TrimSuffix(str, "\n"), "\r")
 }
 
+// Удаление разделителя записи в конце строки (для '\n' также удаляется "\r")
+func trimRecord(str string, delimiter byte) string {
+	if delimiter == '\n' {
+		return trimString(str)
+	}
+	return strings.TrimSuffix(str, string(delimiter))
+}
+
 // Получение текста из io.Reader и сохранение его в структуру типа []*StrokeEntry{}
 func GetText(in io.Reader) ([]*StrokeEntry, error) {
-	reader := bufio.NewReader(in)
+	return GetRecords(in, '\n')
+}
+
+// Последовательное чтение записей, разделённых символом delimiter, из io.Reader
+type RecordReader struct {
+	reader       *bufio.Reader
+	delimiter    byte
+	initialIndex int
+}
+
+func NewRecordReader(in io.Reader, delimiter byte) *RecordReader {
+	return &RecordReader{reader: bufio.NewReader(in), delimiter: delimiter}
+}
+
+// Получение очередной записи (последняя запись может не иметь разделителя), после последней записи - io.EOF
+func (recordReader *RecordReader) Next() (*StrokeEntry, error) {
+	str, err := recordReader.reader.ReadString(recordReader.delimiter)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	trimmed := trimRecord(str, recordReader.delimiter)
+	if err == io.EOF && len(trimmed) == 0 {
+		return nil, io.EOF
+	}
+	entry := &StrokeEntry{Content: Split(trimmed, ' '), Stroke: trimmed, InitialIndex: recordReader.initialIndex, Terminator: str[len(trimmed):]}
+	recordReader.initialIndex++
+	return entry, nil
+}
+
+// Получение записей, разделённых символом delimiter, из io.Reader (последняя запись может не иметь разделителя)
+func GetRecords(in io.Reader, delimiter byte) ([]*StrokeEntry, error) {
+	recordReader := NewRecordReader(in, delimiter)
 	content := []*StrokeEntry{}
-	initialIndex := 0
 	for {
-		str, err := reader.ReadString('\n')
-		if err == nil {
-			trimmed := trimString(str)
-			content = append(content, &StrokeEntry{Content: Split(trimmed, ' '), Stroke: trimmed, InitialIndex: initialIndex})
-			initialIndex++
-			continue
-		}
+		entry, err := recordReader.Next()
 		if err == io.EOF {
-			trimmed := trimString(str)
-			if len(trimmed) == 0 {
-				return content, nil
-			}
-			return append(content, &StrokeEntry{Content: Split(trimmed, ' '), Stroke: trimmed, InitialIndex: initialIndex}), nil
+			return content, nil
 		}
-		return nil, err
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, entry)
+	}
+}
+
+// Получение разделителя записей
+func getDelimiter(options Options) byte {
+	if options.ZeroTerminated {
+		return 0
+	}
+	return '\n'
+}
+
+// Получение разделителя записи для вывода
+func getTerminator(entry *StrokeEntry, options Options) string {
+	delimiter := "\n"
+	if options.ZeroTerminated {
+		delimiter = "\x00"
 	}
+	// Исходный разделитель сохраняется, только если он завершает запись (у последней записи его может не быть)
+	if options.KeepTerminators && strings.HasSuffix(entry.Terminator, delimiter) {
+		return entry.Terminator
+	}
+	return delimiter
 }
 
 // Разделение строки на слова с учётом хвостовых пробелов
@@ -66,69 +121,103 @@ func Split(str string, sep rune) []string {
 
 // Получение только уникальных строк в структуре типа []*StrokeEntry (уникальная строка - строка, у которой значение, по которому
 // производится сортировка, уникально)
-func OnlyUnique(text []*StrokeEntry, column int, ignoreTrailingBlanks bool, valueGetter func([]*StrokeEntry, int, int, bool) string) []*StrokeEntry {
+func OnlyUnique(text []*StrokeEntry, key func(*StrokeEntry) string) []*StrokeEntry {
 	// Реализация типа данных set
 	set := make(map[string]struct{})
-	for i := 0; i < len(text); i++ {
+	// Из строк с одинаковым значением остаётся первая, порядок строк сохраняется
+	unique := text[:0]
+	for _, entry := range text {
 		// Получение значения строки, по которому будет проводиться сортировка
-		value := valueGetter(text, i, column, ignoreTrailingBlanks)
+		value := key(entry)
 		if _, ok := set[value]; ok {
-			n := len(text)
-			text[i] = text[n-1]
-			text[n-1] = nil
-			text = text[:n-1]
-			i--
-		} else {
-			set[value] = struct{}{}
+			continue
 		}
+		set[value] = struct{}{}
+		unique = append(unique, entry)
 	}
-	return text
+	// Обнуление ссылок на удалённые строки
+	clear(text[len(unique):])
+	return unique
+}
+
+// Устойчивая сортировка строк по значению, полученному через keyValue, с использованием comparator
+func SortEntries(text []*StrokeEntry, comparator KeyComparator, keyValue func(*StrokeEntry) string) {
+	slices.SortStableFunc(text, func(a, b *StrokeEntry) int {
+		return comparator.Compare(keyValue(a), keyValue(b))
+	})
+}
+
+// Получение функции, возвращающей значение, по которому сравниваются строки
+func getKeyValueFunc(options Options) func(*StrokeEntry) string {
+	// Лексикографическая сортировка без указания колонки сравнивает строки целиком, остальные режимы - значение колонки
+	wholeLine := options.ComparatorName() == DefaultComparator && options.Column == 0
+	if options.ComparatorName() == "time" {
+		return func(entry *StrokeEntry) string {
+			value, _, _, _ := GetTimeKeySpan(entry, options.Column, options.IgnoreTrailingBlanks)
+			return value
+		}
+	}
+	return func(entry *StrokeEntry) string {
+		return GetKeyValue(entry, options.Column, wholeLine, options.IgnoreTrailingBlanks)
+	}
+}
+
+// Вывод строк (в режиме -debug под каждой строкой выводится значение, по которому она сравнивалась)
+func WriteEntries(writer *bufio.Writer, text []*StrokeEntry, options Options) error {
+	for _, entry := range text {
+		if _, err := writer.WriteString(entry.Stroke); err != nil {
+			return err
+		}
+		if _, err := writer.WriteString(getTerminator(entry, options)); err != nil {
+			return err
+		}
+		if options.Debug {
+			if _, err := writer.WriteString(KeyAnnotation(entry, options)); err != nil {
+				return err
+			}
+			if _, err := writer.WriteString(getTerminator(entry, options)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 // Сортировка текста
-func Sort(in io.Reader, out io.Writer, options Options) error {
+func Sort(in io.Reader, out io.Writer, options Options) (err error) {
+	// Если нужны только первые или последние N строк - частичная сортировка без чтения всего текста в память
+	if !options.CheckIfSorted && (options.Top > 0 || options.Bottom > 0) {
+		return SortTop(in, out, options)
+	}
+
 	writer := bufio.NewWriter(out)
-	defer writer.Flush()
+	// Ошибка записи буферизированного вывода возвращается, если не было других ошибок
+	defer func() {
+		if flushErr := writer.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	// Получение режима сортировки
+	comparator, err := options.Comparator()
+	if err != nil {
+		return err
+	}
 
 	// Получение текста из io.Reader
-	text, err := GetText(in)
+	text, err := GetRecords(in, getDelimiter(options))
 	if err != nil {
 		return err
 	}
 	initLen := len(text)
 
-	switch {
-	case options.Numeric:
-		if options.Unique {
-			text = OnlyUnique(text, options.Column, options.IgnoreTrailingBlanks, GetNumericValue)
-		}
-		// Сортировка по числовому значению
-		NumericSort(text, options.Column, options.IgnoreTrailingBlanks)
-	case options.MonthSort:
-		if options.Unique {
-			text = OnlyUnique(text, options.Column, options.IgnoreTrailingBlanks, GetMonthValue)
-		}
-		// Сортировка по названию месяца
-		MonthSort(text, options.Column, options.IgnoreTrailingBlanks)
-	case options.NumericSuffixes:
-		if options.Unique {
-			text = OnlyUnique(text, options.Column, options.IgnoreTrailingBlanks, GetNumericSuffixesValue)
-		}
-		// Сортировка по числовому значению с учётом суффиксов
-		NumericSuffixesSort(text, options.Column, options.IgnoreTrailingBlanks)
-	case options.Column == 0:
-		if options.Unique {
-			text = OnlyUnique(text, options.Column, options.IgnoreTrailingBlanks, GetDefaultValue)
-		}
-		// Сортировка по строкам
-		DefaultSort(text, options.Column, options.IgnoreTrailingBlanks)
-	default:
-		if options.Unique {
-			text = OnlyUnique(text, options.Column, options.IgnoreTrailingBlanks, GetColumnValue)
-		}
-		// Сортировка по колонке
-		ColumnSort(text, options.Column, options.IgnoreTrailingBlanks)
+	keyValue := getKeyValueFunc(options)
+	if options.Unique {
+		text = OnlyUnique(text, func(entry *StrokeEntry) string {
+			return comparator.Key(keyValue(entry))
+		})
 	}
+	SortEntries(text, comparator, keyValue)
 
 	// Если в обратном порядке - инверсировать результат
 	if options.Reversed {
@@ -140,15 +229,7 @@ func Sort(in io.Reader, out io.Writer, options Options) error {
 
 	// Если не проверка на отсортированность io.Reader - вывод результата
 	if !options.CheckIfSorted {
-		for _, entry := range text {
-			if _, err := writer.WriteString(entry.Stroke); err != nil {
-				return err
-			}
-			if err := writer.WriteByte('\n'); err != nil {
-				return err
-			}
-		}
-		return nil
+		return WriteEntries(writer, text, options)
 	}
 
 	// Проверка на отсортированность io.Reader
@@ -2,6 +2,13 @@ package sort
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -104,6 +111,120 @@ func TestSort(t *testing.T) {
 			expectedOutput: "test2 1    \ntest4 1     \ntest5 1        \n",
 			expectedError:  nil,
 			options:        NewOptions("", 1, false, false, false, false, false, false, false),
+		}, {
+			name:           "NUL-terminated records",
+			inputText:      "./b file\x00./a\nfile\x00./c",
+			expectedOutput: "./a\nfile\x00./b file\x00./c\x00",
+			expectedError:  nil,
+			options:        Options{ZeroTerminated: true},
+		}, {
+			name:           "CRLF endings dropped by default",
+			inputText:      "b\r\na\r\nc",
+			expectedOutput: "a\nb\nc\n",
+			expectedError:  nil,
+			options:        NewOptions("", 0, false, false, false, false, false, false, false),
+		}, {
+			name:           "Keep original endings",
+			inputText:      "c\r\nb\na\r\nd",
+			expectedOutput: "a\r\nb\nc\r\nd\n",
+			expectedError:  nil,
+			options:        Options{KeepTerminators: true},
+		}, {
+			name:           "Keep original endings (last record without terminator)",
+			inputText:      "b\r\na",
+			expectedOutput: "a\nb\r\n",
+			expectedError:  nil,
+			options:        Options{KeepTerminators: true},
+		}, {
+			name:           "IP sort",
+			inputText:      "10.0.0.10\n::1\nhost\n10.0.0.0/8\n10.0.0.2\n10.0.0.0/24\n::ffff:10.0.0.3\n",
+			expectedOutput: "host\n10.0.0.0/8\n10.0.0.0/24\n10.0.0.2\n::ffff:10.0.0.3\n10.0.0.10\n::1\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "ip"},
+		}, {
+			name:           "IP sort unique",
+			inputText:      "10.0.0.1\n::ffff:10.0.0.1\n10.0.0.1/32\n",
+			expectedOutput: "10.0.0.1\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "ip", Unique: true},
+		}, {
+			name:           "Time sort",
+			inputText:      "b 2024-03-01T10:00:00+03:00\na 2024-03-01\nc [01/Mar/2024:08:00:00\nd -\ne 2024-03-01T06:59:59Z\n",
+			expectedOutput: "d -\na 2024-03-01\ne 2024-03-01T06:59:59Z\nb 2024-03-01T10:00:00+03:00\nc [01/Mar/2024:08:00:00\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "time", Column: 1},
+		}, {
+			name:           "Time sort with custom layout",
+			inputText:      "02.01.2024\n01.02.2023\n31.12.2023\n",
+			expectedOutput: "01.02.2023\n31.12.2023\n02.01.2024\n",
+			expectedError:  nil,
+			options:        Options{TimeLayout: "02.01.2006"},
+		}, {
+			name: "Time sort of common log with zones",
+			inputText: "d - - [01/Mar/2024:12:00:00 +0200] \"GET /d\"\n" +
+				"a - - [01/Mar/2024:10:00:00 +0000] \"GET /a\"\n" +
+				"b - - [01/Mar/2024:11:00:00 +0200] \"GET /b\"\n" +
+				"c - - [01/Mar/2024:09:30:00 +0000] \"GET /c\"\n",
+			expectedOutput: "b - - [01/Mar/2024:11:00:00 +0200] \"GET /b\"\n" +
+				"c - - [01/Mar/2024:09:30:00 +0000] \"GET /c\"\n" +
+				"d - - [01/Mar/2024:12:00:00 +0200] \"GET /d\"\n" +
+				"a - - [01/Mar/2024:10:00:00 +0000] \"GET /a\"\n",
+			expectedError: nil,
+			options:       Options{SortMode: "time", Column: 3},
+		}, {
+			name:           "Time sort of common log unique",
+			inputText:      "a [01/Mar/2024:10:00:00 +0000]\nb [01/Mar/2024:12:00:00 +0200]\nc [01/Mar/2024:10:00:00 -0100]\n",
+			expectedOutput: "a [01/Mar/2024:10:00:00 +0000]\nc [01/Mar/2024:10:00:00 -0100]\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "time", Column: 1, Unique: true},
+		}, {
+			name:           "Duration sort",
+			inputText:      "1h30m\n45m\nn/a\n250ms\n-1s\n90m\n",
+			expectedOutput: "n/a\n-1s\n250ms\n45m\n1h30m\n90m\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "duration"},
+		}, {
+			name:           "Duration sort unique",
+			inputText:      "1h30m\n90m\n5400s\n",
+			expectedOutput: "1h30m\n",
+			expectedError:  nil,
+			options:        Options{SortMode: "duration", Unique: true},
+		}, {
+			name:           "Debug whole line",
+			inputText:      "b  \nа\n",
+			expectedOutput: "b  \n_\nа\n_\n",
+			expectedError:  nil,
+			options:        Options{Debug: true, IgnoreTrailingBlanks: true},
+		}, {
+			name:           "Debug column",
+			inputText:      "x 10 y\nлиния 2\nz\n",
+			expectedOutput: "z\n ^ no match for key\nлиния 2\n      _\nx 10 y\n  __\n",
+			expectedError:  nil,
+			options:        Options{Debug: true, Numeric: true, Column: 1},
+		}, {
+			name:           "Debug time with zone",
+			inputText:      "x [01/Mar/2024:10:00:00 +0000] y\n",
+			expectedOutput: "x [01/Mar/2024:10:00:00 +0000] y\n  ____________________________\n",
+			expectedError:  nil,
+			options:        Options{Debug: true, SortMode: "time", Column: 1},
+		}, {
+			name:           "Top numeric reversed",
+			inputText:      "a 5\nb 20\nc 3\nd 20\ne 7\n",
+			expectedOutput: "d 20\nb 20\ne 7\n",
+			expectedError:  nil,
+			options:        Options{Numeric: true, Reversed: true, Column: 1, Top: 3},
+		}, {
+			name:           "Bottom unique",
+			inputText:      "1K\n5M\n2K\n5M\n3G\n",
+			expectedOutput: "3G\n5M\n",
+			expectedError:  nil,
+			options:        Options{NumericSuffixes: true, Unique: true, Bottom: 2},
+		}, {
+			name:           "Top larger than input",
+			inputText:      "b\na\n",
+			expectedOutput: "a\nb\n",
+			expectedError:  nil,
+			options:        Options{Top: 10},
 		},
 	}
 	for _, testCase := range testCases {
@@ -148,12 +269,62 @@ func TestParseArguments(t *testing.T) {
 			name:            "Custom arguments",
 			arguments:       []string{"-k", "2", "-M", "-u", "-b", "-c", "./filepath.txt"},
 			expectedError:   nil,
-			expectedOptions: NewOptions("./filepath.txt", 1, false, true, false, false, true, true, true),
+			expectedOptions: Options{Filepath: "./filepath.txt", Column: 1, ColumnGiven: true, MonthSort: true, Unique: true, IgnoreTrailingBlanks: true, CheckIfSorted: true},
+		}, {
+			name:            "Explicit first column",
+			arguments:       []string{"-k", "1", "-b", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", Column: 0, ColumnGiven: true, IgnoreTrailingBlanks: true},
 		}, {
 			name:            "Non positive column",
 			arguments:       []string{"-k", "-1", "./filepath.txt"},
 			expectedError:   ErrNonPositiveColumn,
 			expectedOptions: NewOptions("", 0, false, false, false, false, false, false, false),
+		}, {
+			name:            "Output file",
+			arguments:       []string{"-n", "-o", "./filepath.txt", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", Numeric: true, Output: "./filepath.txt"},
+		}, {
+			name:            "Record delimiters",
+			arguments:       []string{"-z", "-keep-endings", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", ZeroTerminated: true, KeepTerminators: true},
+		}, {
+			name:            "Sort mode by name",
+			arguments:       []string{"-sort", "month", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", SortMode: "month"},
+		}, {
+			name:            "Time layout",
+			arguments:       []string{"-k", "2", "-time-layout", "02.01.2006", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", Column: 1, ColumnGiven: true, TimeLayout: "02.01.2006"},
+		}, {
+			name:            "Top",
+			arguments:       []string{"-n", "-r", "-top", "20", "./filepath.txt"},
+			expectedError:   nil,
+			expectedOptions: Options{Filepath: "./filepath.txt", Numeric: true, Reversed: true, Top: 20},
+		}, {
+			name:            "Negative bottom",
+			arguments:       []string{"-bottom", "-1", "./filepath.txt"},
+			expectedError:   ErrNegativeLimit,
+			expectedOptions: Options{},
+		}, {
+			name:            "Top with check",
+			arguments:       []string{"-c", "-top", "1", "./filepath.txt"},
+			expectedError:   ErrIncompatibleLimit,
+			expectedOptions: Options{},
+		}, {
+			name:            "Check with output",
+			arguments:       []string{"-c", "-o", "./filepath.txt", "./filepath.txt"},
+			expectedError:   ErrCheckWithOutput,
+			expectedOptions: Options{},
+		}, {
+			name:            "Unknown sort mode",
+			arguments:       []string{"-sort", "unknown", "./filepath.txt"},
+			expectedError:   ErrUnknownComparator,
+			expectedOptions: Options{},
 		},
 	}
 
@@ -162,7 +333,7 @@ func TestParseArguments(t *testing.T) {
 			got, err := ParseArguments(testCase.arguments)
 
 			if testCase.expectedError != nil {
-				if err == nil || err.Error() != testCase.expectedError.Error() {
+				if !errors.Is(err, testCase.expectedError) {
 					t.Errorf("error: got %v, want %v", err, testCase.expectedError)
 				}
 			} else {
@@ -177,3 +348,258 @@ func TestParseArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestSortToFile(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inputText      string
+		expectedOutput string
+		inPlace        bool
+		mode           os.FileMode
+		expectedFiles  int
+		options        Options
+	}{
+		{
+			name:           "In place sort",
+			inputText:      "3\n1\n2\n",
+			expectedOutput: "1\n2\n3\n",
+			inPlace:        true,
+			mode:           0640,
+			expectedFiles:  1,
+			options:        NewOptions("", 0, true, false, false, false, false, false, false),
+		}, {
+			name:           "Existing output file",
+			inputText:      "b\na\n",
+			expectedOutput: "a\nb\n",
+			inPlace:        false,
+			mode:           0600,
+			expectedFiles:  2,
+			options:        NewOptions("", 0, false, false, false, false, false, false, false),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			directory := t.TempDir()
+			inputPath := filepath.Join(directory, "input.txt")
+			outputPath := filepath.Join(directory, "output.txt")
+			if testCase.inPlace {
+				outputPath = inputPath
+			}
+			if err := os.WriteFile(inputPath, []byte(testCase.inputText), testCase.mode); err != nil {
+				t.Fatal(err)
+			}
+			if !testCase.inPlace {
+				if err := os.WriteFile(outputPath, []byte("old content\n"), testCase.mode); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			file, err := os.Open(inputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			if err := SortToFile(file, outputPath, testCase.options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+
+			got, err := os.ReadFile(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != testCase.expectedOutput {
+				t.Errorf("got %s, want %s", got, testCase.expectedOutput)
+			}
+			info, err := os.Stat(outputPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != testCase.mode {
+				t.Errorf("mode: got %v, want %v", info.Mode().Perm(), testCase.mode)
+			}
+			// Временные файлы не должны оставаться в директории
+			entries, err := os.ReadDir(directory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != testCase.expectedFiles {
+				t.Errorf("files in directory: got %d, want %d", len(entries), testCase.expectedFiles)
+			}
+		})
+	}
+}
+
+var errWrite error = errors.New("no space left on device")
+
+// Writer, запись в который всегда завершается ошибкой
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestSortWriteError(t *testing.T) {
+	// Вывод меньше размера буфера записывается только при сбросе буфера - его ошибка не должна теряться
+	for _, options := range []Options{{}, {Top: 2}, {CheckIfSorted: true}} {
+		if err := Sort(strings.NewReader("b\na\n"), failingWriter{}, options); !errors.Is(err, errWrite) {
+			t.Errorf("%+v: error: got %v, want %v", options, err, errWrite)
+		}
+	}
+
+	// При ошибке записи целевой файл не заменяется, временный файл удаляется
+	directory := t.TempDir()
+	path := filepath.Join(directory, "data.txt")
+	if err := os.WriteFile(path, []byte("b\na\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := WriteFileAtomic(path, func(out io.Writer) error {
+		return Sort(strings.NewReader("b\na\n"), io.MultiWriter(out, failingWriter{}), Options{})
+	})
+	if !errors.Is(err, errWrite) {
+		t.Errorf("error: got %v, want %v", err, errWrite)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "b\na\n" {
+		t.Errorf("file: got %q (%v), want %q", got, err, "b\na\n")
+	}
+	if entries, err := os.ReadDir(directory); err != nil || len(entries) != 1 {
+		t.Errorf("files in directory: got %d (%v), want %d", len(entries), err, 1)
+	}
+}
+
+func TestRegisterComparator(t *testing.T) {
+	// Сортировка по длине значения (строки одинаковой длины считаются повторяющимися)
+	length := ComparatorFuncs{
+		CompareFunc: func(a, b string) int { return len(a) - len(b) },
+		KeyFunc:     func(value string) string { return strings.Repeat(" ", len(value)) },
+	}
+	if err := RegisterComparator("test-length", length); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	t.Cleanup(func() { unregisterComparator("test-length") })
+	if err := RegisterComparator("test-length", length); !errors.Is(err, ErrComparatorExists) {
+		t.Errorf("error: got %v, want %v", err, ErrComparatorExists)
+	}
+	if err := RegisterComparator("", length); !errors.Is(err, ErrInvalidComparator) {
+		t.Errorf("error: got %v, want %v", err, ErrInvalidComparator)
+	}
+
+	testCases := []struct {
+		name           string
+		inputText      string
+		expectedOutput string
+		options        Options
+	}{
+		{
+			name:           "Registered sort mode",
+			inputText:      "ccc\na\nbb\n",
+			expectedOutput: "a\nbb\nccc\n",
+			options:        Options{SortMode: "test-length"},
+		}, {
+			name:           "Registered sort mode by column (unique, reversed)",
+			inputText:      "1 ccc\n2 a\n3 bb\n4 dd\n",
+			expectedOutput: "1 ccc\n3 bb\n2 a\n",
+			options:        Options{SortMode: "test-length", Column: 1, Unique: true, Reversed: true},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := Sort(strings.NewReader(testCase.inputText), &buffer, testCase.options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("got %s, want %s", got, testCase.expectedOutput)
+			}
+		})
+	}
+
+	if err := Sort(strings.NewReader(""), &bytes.Buffer{}, Options{SortMode: "unknown"}); !errors.Is(err, ErrUnknownComparator) {
+		t.Errorf("error: got %v, want %v", err, ErrUnknownComparator)
+	}
+}
+
+func TestDebugWarnings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  Options
+		expected []string
+	}{
+		{
+			name:     "No warnings",
+			options:  Options{Column: 1, ColumnGiven: true, IgnoreTrailingBlanks: true},
+			expected: []string{"text ordering performed using simple byte comparison"},
+		}, {
+			name:     "-b with explicit first column",
+			options:  Options{Column: 0, ColumnGiven: true, IgnoreTrailingBlanks: true},
+			expected: []string{"text ordering performed using simple byte comparison"},
+		}, {
+			name:    "Incompatible modes and -b without -k",
+			options: Options{Numeric: true, MonthSort: true, IgnoreTrailingBlanks: true},
+			expected: []string{
+				"options '-n' '-M' are incompatible; only '-n' is used",
+				"option '-b' is used without '-k'; trailing blanks are ignored for the whole key",
+				"ordering performed using 'numeric' sort mode",
+			},
+		}, {
+			name:    "Ignored time layout",
+			options: Options{SortMode: "ip", TimeLayout: "2006"},
+			expected: []string{
+				"option '-time-layout' is ignored in 'ip' sort mode",
+				"ordering performed using 'ip' sort mode",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := DebugWarnings(testCase.options)
+			if !slices.Equal(got, testCase.expected) {
+				t.Errorf("got %q, want %q", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestSortTopMatchesSort(t *testing.T) {
+	// Частичная сортировка должна давать тот же результат, что и полная сортировка с обрезкой (sort | head, sort | tail)
+	random := rand.New(rand.NewSource(1))
+	lines := make([]string, 500)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%c %d%c", 'a'+random.Intn(5), random.Intn(50), "KMG"[random.Intn(3)])
+	}
+	input := strings.Join(lines, "\n") + "\n"
+
+	for _, options := range []Options{
+		{},
+		{Column: 1, Numeric: true},
+		{Column: 1, NumericSuffixes: true, Reversed: true},
+		{Column: 1, Numeric: true, Unique: true},
+		{Column: 1, NumericSuffixes: true, Unique: true, Reversed: true},
+		{Unique: true},
+	} {
+		var full bytes.Buffer
+		if err := Sort(strings.NewReader(input), &full, options); err != nil {
+			t.Fatal(err)
+		}
+		sorted := strings.SplitAfter(full.String(), "\n")
+		sorted = sorted[:len(sorted)-1]
+
+		for _, limit := range []int{1, 7, 20, len(lines) + 1} {
+			top, bottom := options, options
+			top.Top, bottom.Bottom = limit, limit
+			var gotTop, gotBottom bytes.Buffer
+			if err := Sort(strings.NewReader(input), &gotTop, top); err != nil {
+				t.Fatal(err)
+			}
+			if err := Sort(strings.NewReader(input), &gotBottom, bottom); err != nil {
+				t.Fatal(err)
+			}
+			n := min(limit, len(sorted))
+			if expected := strings.Join(sorted[:n], ""); gotTop.String() != expected {
+				t.Errorf("top %d %+v: got %q, want %q", limit, options, gotTop.String(), expected)
+			}
+			if expected := strings.Join(sorted[len(sorted)-n:], ""); gotBottom.String() != expected {
+				t.Errorf("bottom %d %+v: got %q, want %q", limit, options, gotBottom.String(), expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package sort
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Форматы даты и времени, распознаваемые по умолчанию (ISO-8601/RFC3339 и время из журналов веб-серверов).
+// Время без часового пояса считается временем UTC
+var DefaultTimeLayouts []string = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	time.DateOnly,
+	"20060102T150405Z0700",
+	"02/Jan/2006:15:04:05 -0700",
+	"02/Jan/2006:15:04:05",
+}
+
+// Смещение часового пояса в журналах в общем формате (common log): в "[10/Oct/2000:13:55:36 -0700]"
+// оно отделено пробелом и при разделении строки попадает в следующую колонку
+var commonLogZone *regexp.Regexp = regexp.MustCompile(`^[+-]\d{4}\]$`)
+
+// Получение значения и границ (в байтах внутри entry.Stroke) ключа для режима "time": если за колонкой
+// следует смещение часового пояса журнала в общем формате, оно входит в ключ (ok == false, если колонки
+// в строке нет). С -time-layout формат таких значений должен содержать смещение ("02/Jan/2006:15:04:05 -0700")
+func GetTimeKeySpan(entry *StrokeEntry, column int, ignoreTrailingBlanks bool) (value string, start, end int, ok bool) {
+	start, end, ok = GetKeySpan(entry, column, false, ignoreTrailingBlanks)
+	if !ok {
+		return "", 0, 0, false
+	}
+	value = entry.Stroke[start:end]
+	if column+1 < len(entry.Content) {
+		zone := strings.TrimRight(entry.Content[column+1], " ")
+		if commonLogZone.MatchString(zone) {
+			// Колонки разделены одним пробельным символом (см. Split())
+			end = start + len(entry.Content[column]) + 1 + len(zone)
+			value = strings.TrimRight(entry.Content[column], " ") + " " + zone
+		}
+	}
+	return value, start, end, true
+}
+
+// Сравнение по дате и времени. Значения, которые не удалось разобрать ни в одном из форматов, идут первыми
+type TimeComparator struct {
+	// Форматы в нотации пакета time (если пусто - DefaultTimeLayouts)
+	Layouts []string
+}
+
+func NewTimeComparator(layouts ...string) TimeComparator {
+	return TimeComparator{Layouts: layouts}
+}
+
+// Разбор даты и времени (квадратные скобки, как в "[10/Oct/2000:13:55:36 -0700]", отбрасываются)
+func (comparator TimeComparator) Parse(value string) (time.Time, bool) {
+	value = strings.Trim(value, "[]")
+	layouts := comparator.Layouts
+	if len(layouts) == 0 {
+		layouts = DefaultTimeLayouts
+	}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (comparator TimeComparator) Compare(a, b string) int {
+	timeA, okA := comparator.Parse(a)
+	timeB, okB := comparator.Parse(b)
+	if !okA || !okB {
+		return compareValidity(okA, okB)
+	}
+	return timeA.Compare(timeB)
+}
+
+// Один момент времени в разных часовых поясах считается одинаковым
+func (comparator TimeComparator) Key(value string) string {
+	if parsed, ok := comparator.Parse(value); ok {
+		return parsed.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package sort
+
+import (
+	"bufio"
+	"container/heap"
+	"io"
+	"slices"
+)
+
+// Ограниченная куча строк (в корне - строка, которая первой будет вытеснена из кучи)
+type entryHeap struct {
+	entries []*StrokeEntry
+	worse   func(a, b *StrokeEntry) bool
+}
+
+func (h *entryHeap) Len() int           { return len(h.entries) }
+func (h *entryHeap) Less(i, j int) bool { return h.worse(h.entries[i], h.entries[j]) }
+func (h *entryHeap) Swap(i, j int)      { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+func (h *entryHeap) Push(x any)         { h.entries = append(h.entries, x.(*StrokeEntry)) }
+
+func (h *entryHeap) Pop() any {
+	n := len(h.entries)
+	entry := h.entries[n-1]
+	h.entries[n-1] = nil
+	h.entries = h.entries[:n-1]
+	return entry
+}
+
+// Частичная сортировка: вывод только первых (-top) или последних (-bottom) N строк отсортированного текста.
+// Текст читается потоково, в памяти хранится не более N строк (время O(n log N), память O(N))
+func SortTop(in io.Reader, out io.Writer, options Options) (err error) {
+	writer := bufio.NewWriter(out)
+	// Ошибка записи буферизированного вывода возвращается, если не было других ошибок
+	defer func() {
+		if flushErr := writer.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	comparator, err := options.Comparator()
+	if err != nil {
+		return err
+	}
+	keyValue := getKeyValueFunc(options)
+
+	// Порядок вывода совпадает с Sort(): по значению, при равенстве - по порядку во входных данных, с -r - обратный
+	order := func(a, b *StrokeEntry) int {
+		result := comparator.Compare(keyValue(a), keyValue(b))
+		if result == 0 {
+			result = a.InitialIndex - b.InitialIndex
+		}
+		if options.Reversed {
+			return -result
+		}
+		return result
+	}
+
+	// Для -top вытесняются строки, идущие в выводе позже, для -bottom - раньше
+	limit := options.Top
+	worse := func(a, b *StrokeEntry) bool { return order(a, b) > 0 }
+	if options.Bottom > 0 {
+		limit = options.Bottom
+		worse = func(a, b *StrokeEntry) bool { return order(a, b) < 0 }
+	}
+	selected := &entryHeap{entries: make([]*StrokeEntry, 0, limit), worse: worse}
+
+	// Ключи строк, находящихся в куче (для -u). Строка с ключом, который уже был вытеснен, хуже всех строк в куче,
+	// поэтому хранить ключи вытесненных строк не нужно
+	keys := make(map[string]struct{})
+
+	recordReader := NewRecordReader(in, getDelimiter(options))
+	for {
+		entry, err := recordReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		key := ""
+		if options.Unique {
+			key = comparator.Key(keyValue(entry))
+			// Из строк с одинаковым ключом остаётся первая
+			if _, ok := keys[key]; ok {
+				continue
+			}
+		}
+
+		switch {
+		case selected.Len() < limit:
+			heap.Push(selected, entry)
+		case worse(selected.entries[0], entry):
+			if options.Unique {
+				delete(keys, comparator.Key(keyValue(selected.entries[0])))
+			}
+			selected.entries[0] = entry
+			heap.Fix(selected, 0)
+		default:
+			continue
+		}
+		if options.Unique {
+			keys[key] = struct{}{}
+		}
+	}
+
+	text := selected.entries
+	slices.SortFunc(text, order)
+	return WriteEntries(writer, text, options)
+}
@@ -27,6 +27,14 @@ import (
 -b — игнорировать хвостовые пробелы
 -c — проверять отсортированы ли данные
 -h — сортировать по числовому значению с учётом суффиксов
+-o — записать результат в файл (в том числе во входной), нельзя использовать с -c
+-z — строки разделены символом NUL, а не переводом строки
+-keep-endings — сохранять исходные окончания строк (например, "\r\n")
+-sort — сортировать в зарегистрированном режиме (default, numeric, month, human-numeric, ip, time, duration или добавленном
+через sort.RegisterComparator)
+-time-layout — формат даты и времени для режима time (в нотации пакета time)
+-top, -bottom — вывести только первые или последние N строк отсортированного текста (частичная сортировка)
+-debug — подчеркнуть часть строки, по которой она сортировалась, и предупредить о сомнительных сочетаниях флагов
 
 Программа должна проходить все тесты. Код должен проходить проверки go vet и golint.
 */
@@ -37,13 +45,25 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 		return
 	}
+	// В режиме -debug - вывод предупреждений о сочетаниях флагов
+	if options.Debug {
+		for _, warning := range sort.DebugWarnings(options) {
+			fmt.Fprintln(os.Stderr, "sort:", warning)
+		}
+	}
 	file, err := os.Open(options.Filepath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return
 	}
 	defer file.Close()
-	if err := sort.Sort(file, os.Stdout, options); err != nil {
+	// Если указан выходной файл - атомарная запись результата в файл
+	if options.Output != "" {
+		err = sort.SortToFile(file, options.Output, options)
+	} else {
+		err = sort.Sort(file, os.Stdout, options)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 	}
 }
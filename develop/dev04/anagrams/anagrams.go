@@ -30,6 +30,12 @@ func SortAnagramsMap(anagramsMap map[string][]string) {
 
 // Построение map множества анаграмм
 func AnagramsMap(words *[]string) *map[string][]string {
+	return AnagramsMapNormalized(words, Normalizer{})
+}
+
+// Построение map множества анаграмм с нормализацией слов (в результат попадают слова в исходном написании,
+// приведённые к нижнему регистру)
+func AnagramsMapNormalized(words *[]string, normalizer Normalizer) *map[string][]string {
 	// map, хранящий результат выполнения функции
 	result := make(map[string][]string)
 
@@ -48,8 +54,8 @@ func AnagramsMap(words *[]string) *map[string][]string {
 		}
 		wordsSet[word] = struct{}{}
 
-		// Сортировка символов в слове
-		sortedLetters := SortLetters(word)
+		// Сортировка символов в нормализованном слове
+		sortedLetters := normalizer.Signature(word)
 
 		// Если не найдено ключа для sortedLetters, word - ключ
 		if _, ok := sortedWordKeyMap[sortedLetters]; !ok {
@@ -1,7 +1,13 @@
 package anagrams
 
 import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -132,3 +138,544 @@ func TestSortAnagramsMap(t *testing.T) {
 		})
 	}
 }
+
+func TestIndex(t *testing.T) {
+	dictionary := "пятак\nпятка\n\nТяпка\nлисток\r\nслиток\nстолик\nпятак\nкот\n"
+	index, err := BuildIndex(strings.NewReader(dictionary))
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+
+	// Проверка индекса до и после сохранения в бинарном формате
+	var buffer bytes.Buffer
+	if _, err := index.WriteTo(&buffer); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	loaded, err := ReadIndex(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+
+	testCases := []struct {
+		name     string
+		word     string
+		expected []string
+	}{
+		{
+			name:     "Word from dictionary",
+			word:     "пятка",
+			expected: []string{"пятак", "пятка", "тяпка"},
+		}, {
+			name:     "Word not from dictionary",
+			word:     "КАПЯТ",
+			expected: []string{"пятак", "пятка", "тяпка"},
+		}, {
+			name:     "Word without anagrams",
+			word:     "ток",
+			expected: []string{"кот"},
+		}, {
+			name:     "Unknown letters",
+			word:     "собака",
+			expected: nil,
+		},
+	}
+	for _, current := range []*Index{index, loaded} {
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				got := current.Lookup(testCase.word)
+				if !reflect.DeepEqual(got, testCase.expected) {
+					t.Errorf("got %v, want %v", got, testCase.expected)
+				}
+			})
+		}
+		expectedMap := map[string][]string{"пятак": {"пятак", "пятка", "тяпка"}, "листок": {"листок", "слиток", "столик"}}
+		if got := current.AnagramsMap(); !reflect.DeepEqual(got, expectedMap) {
+			t.Errorf("got %v, want %v", got, expectedMap)
+		}
+		if current.Len() != 3 {
+			t.Errorf("len: got %v, want %v", current.Len(), 3)
+		}
+	}
+}
+
+func TestIndexFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.bin")
+	words := []string{"листок", "слиток", "столик"}
+	if err := NewIndex(words).SaveFile(path); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	index, err := LoadIndexFile(path)
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	if got := index.Lookup("толиск"); !reflect.DeepEqual(got, words) {
+		t.Errorf("got %v, want %v", got, words)
+	}
+
+	// Правила нормализации сохраняются в индексе
+	dictionary := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(dictionary, []byte("ёлка\nкелА\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	normalizer := Normalizer{Form: NFC, FoldYo: true}
+	built, err := BuildIndexFileNormalized(dictionary, normalizer)
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	if err := built.SaveFile(path); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	if index, err = LoadIndexFile(path); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	if got := index.Normalizer(); got != normalizer {
+		t.Errorf("normalizer: got %+v, want %+v", got, normalizer)
+	}
+	if got, want := index.Lookup("лека"), []string{"кела", "ёлка"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReadIndexInvalid(t *testing.T) {
+	var buffer bytes.Buffer
+	if _, err := NewIndex([]string{"пятак", "пятка"}).WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	valid := buffer.Bytes()
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty data", data: []byte{}},
+		{name: "Wrong header", data: append([]byte("XXXX"), valid[4:]...)},
+		{name: "Truncated data", data: valid[:len(valid)-3]},
+		{name: "Wrong key position", data: append(append([]byte{}, valid[:6]...), 1, 1, 5)},
+		{name: "Unknown normalizer flags", data: append(append([]byte{}, valid[:5]...), 0xFF, 0)},
+		{name: "Unknown version", data: append([]byte("AIDX"), 9, 0, 0)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := ReadIndex(bytes.NewReader(testCase.data)); err != ErrInvalidIndex {
+				t.Errorf("error: got %v, want %v", err, ErrInvalidIndex)
+			}
+		})
+	}
+}
+
+func TestFindSubAnagrams(t *testing.T) {
+	index := NewIndex([]string{"кот", "ток", "кто", "око", "кок", "рот", "тор", "рок", "трек", "т"})
+	testCases := []struct {
+		name     string
+		letters  string
+		expected []string
+	}{
+		{
+			name:     "Common letters",
+			letters:  "отк",
+			expected: []string{"кот", "кто", "т", "ток"},
+		}, {
+			name:     "Repeated letters",
+			letters:  "оркто",
+			expected: []string{"кот", "кто", "око", "рок", "рот", "т", "ток", "тор"},
+		}, {
+			name:     "Case and spaces",
+			letters:  "К О К",
+			expected: []string{"кок"},
+		}, {
+			name:     "No words",
+			letters:  "аб",
+			expected: []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := index.FindSubAnagrams(testCase.letters)
+			if !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("got %v, want %v", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestFindPhraseAnagrams(t *testing.T) {
+	index := NewIndex([]string{"кот", "ток", "рок", "кит", "ор", "рот", "к", "о", "т"})
+	testCases := []struct {
+		name     string
+		phrase   string
+		maxWords int
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "Two words",
+			phrase:   "ток рок",
+			maxWords: 2,
+			limit:    0,
+			expected: []string{"кот рок", "ток рок"},
+		}, {
+			name:     "Single word",
+			phrase:   "отк",
+			maxWords: 1,
+			limit:    0,
+			expected: []string{"кот", "ток"},
+		}, {
+			name:     "Repeated words without permutations",
+			phrase:   "кк",
+			maxWords: 3,
+			limit:    0,
+			expected: []string{"к к"},
+		}, {
+			name:     "Result limit",
+			phrase:   "кот",
+			maxWords: 3,
+			limit:    2,
+			expected: []string{"кот", "ток"},
+		}, {
+			name:     "No phrases",
+			phrase:   "кошка",
+			maxWords: 3,
+			limit:    0,
+			expected: []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := index.FindPhraseAnagrams(testCase.phrase, testCase.maxWords, testCase.limit)
+			if !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("got %v, want %v", got, testCase.expected)
+			}
+		})
+	}
+}
+
+// Строчные буквы русского алфавита
+const russianLetters = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+
+// Генерация словаря из случайных слов из букв alphabet
+func randomWords(n int, seed int64, alphabet string) []string {
+	random := rand.New(rand.NewSource(seed))
+	letters := []rune(alphabet)
+	words := make([]string, n)
+	for i := range words {
+		word := make([]rune, 2+random.Intn(5))
+		for j := range word {
+			word[j] = letters[random.Intn(len(letters))]
+		}
+		words[i] = string(word)
+	}
+	return words
+}
+
+func TestAnagramsMapParallel(t *testing.T) {
+	testCases := []struct {
+		name    string
+		words   []string
+		workers int
+	}{
+		{
+			name:    "Standard test from the condition",
+			words:   []string{"пятак", "пятка", "тяпка", "листок", "слиток", "столик"},
+			workers: 4,
+		}, {
+			name:    "Words order",
+			words:   []string{"пятка", "столик", "тяпка", "пятак", "листок", "слиток"},
+			workers: 3,
+		}, {
+			name:    "More workers than words",
+			words:   []string{"пятак", "пяТАК", "пятка"},
+			workers: 8,
+		}, {
+			name:    "Empty words slice",
+			words:   []string{},
+			workers: 2,
+		}, {
+			name:    "Random words",
+			words:   randomWords(20000, 1, russianLetters+"abcZ-"),
+			workers: 0,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			expected := AnagramsMap(&testCase.words)
+			got := AnagramsMapParallel(&testCase.words, testCase.workers)
+			if !reflect.DeepEqual(*got, *expected) {
+				t.Errorf("got %v, want %v", *got, *expected)
+			}
+		})
+	}
+}
+
+func TestCountSignature(t *testing.T) {
+	testCases := []struct {
+		name  string
+		a     string
+		b     string
+		equal bool
+	}{
+		{name: "Anagrams", a: "пятак", b: "тяпка", equal: true},
+		{name: "Latin anagrams", a: "listen", b: "silent", equal: true},
+		{name: "Not anagrams", a: "пятак", b: "пятаа", equal: false},
+		{name: "Letter outside small alphabet", a: "ÿa-b", b: "b-aÿ", equal: true},
+		{name: "Different alphabets", a: "ab", b: "a-b", equal: false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := CountSignature(testCase.a) == CountSignature(testCase.b); got != testCase.equal {
+				t.Errorf("got %v, want %v", got, testCase.equal)
+			}
+		})
+	}
+}
+
+func BenchmarkSortLetters(b *testing.B) {
+	words := randomWords(1000, 2, russianLetters)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, word := range words {
+			SortLetters(word)
+		}
+	}
+}
+
+func BenchmarkCountSignature(b *testing.B) {
+	words := randomWords(1000, 2, russianLetters)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, word := range words {
+			CountSignature(word)
+		}
+	}
+}
+
+func BenchmarkAnagramsMap(b *testing.B) {
+	words := randomWords(200000, 3, russianLetters)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AnagramsMap(&words)
+	}
+}
+
+func BenchmarkAnagramsMapParallel(b *testing.B) {
+	words := randomWords(200000, 3, russianLetters)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		AnagramsMapParallel(&words, 0)
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		normalizer Normalizer
+		word       string
+		expected   string
+	}{
+		{
+			name:       "Only lower case",
+			normalizer: Normalizer{},
+			word:       "ЁЛКА-Палка",
+			expected:   "ёлка-палка",
+		}, {
+			name:       "NFC",
+			normalizer: Normalizer{Form: NFC},
+			word:       "е\u0308лка",
+			expected:   "ёлка",
+		}, {
+			name:       "NFKC",
+			normalizer: Normalizer{Form: NFKC},
+			word:       "ﬁＡ",
+			expected:   "fia",
+		}, {
+			name:       "Yo folding",
+			normalizer: Normalizer{FoldYo: true},
+			word:       "Ёлка и е\u0308ж",
+			expected:   "елка и еж",
+		}, {
+			name:       "Strip punctuation",
+			normalizer: Normalizer{StripPunctuation: true},
+			word:       "кто-то d'Artagnan «ой»",
+			expected:   "ктотоdartagnanой",
+		}, {
+			name:       "Strip diacritics",
+			normalizer: Normalizer{StripDiacritics: true},
+			word:       "Café naïve йод ёж",
+			expected:   "cafe naive йод ёж",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := testCase.normalizer.Normalize(testCase.word); got != testCase.expected {
+				t.Errorf("got %q, want %q", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestAnagramsMapNormalized(t *testing.T) {
+	normalizer := Normalizer{Form: NFC, FoldYo: true, StripPunctuation: true, StripDiacritics: true}
+	testCases := []struct {
+		name     string
+		words    []string
+		expected map[string][]string
+	}{
+		{
+			name:     "Yo folding keeps original spelling",
+			words:    []string{"ёлка", "лека", "ЕЛКА"},
+			expected: map[string][]string{"ёлка": {"елка", "лека", "ёлка"}},
+		}, {
+			name:     "Decomposed input",
+			words:    []string{"е\u0308ж", "жё"},
+			expected: map[string][]string{"е\u0308ж": {"е\u0308ж", "жё"}},
+		}, {
+			name:     "Punctuation and diacritics",
+			words:    []string{"кое-кто", "котоке", "café", "face"},
+			expected: map[string][]string{"кое-кто": {"кое-кто", "котоке"}, "café": {"café", "face"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := AnagramsMapNormalized(&testCase.words, normalizer)
+			if !reflect.DeepEqual(*got, testCase.expected) {
+				t.Errorf("got %v, want %v", *got, testCase.expected)
+			}
+			parallel := AnagramsMapParallelNormalized(&testCase.words, 2, normalizer)
+			if !reflect.DeepEqual(*parallel, testCase.expected) {
+				t.Errorf("parallel: got %v, want %v", *parallel, testCase.expected)
+			}
+		})
+	}
+
+	// Индекс сохраняет правила нормализации в бинарном формате
+	var buffer bytes.Buffer
+	if _, err := NewIndexNormalized([]string{"ёлка", "лека"}, normalizer).WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	index, err := ReadIndex(&buffer)
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	if index.Normalizer() != normalizer {
+		t.Errorf("normalizer: got %+v, want %+v", index.Normalizer(), normalizer)
+	}
+	expected := []string{"лека", "ёлка"}
+	if got := index.Lookup("Лёк-а"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v, want %v", got, expected)
+	}
+}
+
+func TestParseArguments(t *testing.T) {
+	testCases := []struct {
+		name      string
+		arguments []string
+		expected  Options
+		err       error
+	}{
+		{
+			name:      "Defaults",
+			arguments: []string{"dict.txt"},
+			expected:  NewOptions([]string{"dict.txt"}, 2, FormatText, "", Normalizer{}),
+		}, {
+			name:      "All flags",
+			arguments: []string{"-min", "1", "-format", "csv", "-q", "пятак", "-norm", "nfkc", "-yo", "-punct", "-diacritics", "a.txt", "b.txt"},
+			expected:  NewOptions([]string{"a.txt", "b.txt"}, 1, FormatCSV, "пятак", Normalizer{Form: NFKC, FoldYo: true, StripPunctuation: true, StripDiacritics: true}),
+		}, {
+			name:      "Non-positive min",
+			arguments: []string{"-min", "0"},
+			err:       ErrNonPositiveMinSize,
+		}, {
+			name:      "Unknown format",
+			arguments: []string{"-format", "xml"},
+			err:       ErrUnknownFormat,
+		}, {
+			name:      "HTTP without dictionary",
+			arguments: []string{"-http", "localhost:8080"},
+			err:       ErrNoDictionary,
+		}, {
+			name:      "Unknown normalization",
+			arguments: []string{"-norm", "nfd"},
+			err:       ErrUnknownNormalization,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := ParseArguments(testCase.arguments)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("error: got %v, want %v", err, testCase.err)
+			}
+			if !reflect.DeepEqual(got, testCase.expected) && testCase.err == nil {
+				t.Errorf("got %+v, want %+v", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestPrintAnagrams(t *testing.T) {
+	index, err := BuildIndex(strings.NewReader("Тяпка\nлисток\nпятак\n\nслиток\nкот\nпятка\nстолик\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name     string
+		options  Options
+		expected string
+		err      error
+	}{
+		{
+			name:     "Text",
+			options:  NewOptions(nil, 2, FormatText, "", Normalizer{}),
+			expected: "листок: листок слиток столик\nтяпка: пятак пятка тяпка\n",
+		}, {
+			name:     "Text with single words",
+			options:  NewOptions(nil, 1, FormatText, "", Normalizer{}),
+			expected: "кот: кот\nлисток: листок слиток столик\nтяпка: пятак пятка тяпка\n",
+		}, {
+			name:     "JSON",
+			options:  NewOptions(nil, 2, FormatJSON, "", Normalizer{}),
+			expected: `{"листок":["листок","слиток","столик"],"тяпка":["пятак","пятка","тяпка"]}` + "\n",
+		}, {
+			name:     "CSV",
+			options:  NewOptions(nil, 3, FormatCSV, "", Normalizer{}),
+			expected: "листок,листок,слиток,столик\nтяпка,пятак,пятка,тяпка\n",
+		}, {
+			name:     "Query",
+			options:  NewOptions(nil, 2, FormatText, "Тапяк", Normalizer{}),
+			expected: "тяпка: пятак пятка тяпка\n",
+		}, {
+			name:    "Query not found",
+			options: NewOptions(nil, 2, FormatText, "собака", Normalizer{}),
+			err:     ErrWordNotFound,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := PrintAnagrams(index, &out, testCase.options)
+			if !errors.Is(err, testCase.err) {
+				t.Fatalf("error: got %v, want %v", err, testCase.err)
+			}
+			if got := out.String(); got != testCase.expected {
+				t.Errorf("got %q, want %q", got, testCase.expected)
+			}
+		})
+	}
+}
+
+var errWrite error = errors.New("no space left on device")
+
+// Writer, запись в который всегда завершается ошибкой
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestWriteGroupsError(t *testing.T) {
+	// Вывод меньше размера буфера записывается только при сбросе буфера - его ошибка не должна теряться
+	groups := []Group{{Key: "тяпка", Words: []string{"пятак", "пятка", "тяпка"}}}
+	for _, format := range []string{FormatText, FormatJSON, FormatCSV} {
+		if err := WriteGroups(failingWriter{}, groups, format); !errors.Is(err, errWrite) {
+			t.Errorf("%s: error: got %v, want %v", format, err, errWrite)
+		}
+	}
+}
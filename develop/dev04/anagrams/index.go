@@ -0,0 +1,323 @@
+package anagrams
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidIndex error = errors.New("invalid anagram index data")
+
+// Заголовок и версия бинарного формата индекса
+const (
+	indexMagic   = "AIDX"
+	indexVersion = 1
+	// Максимальная длина слова в байтах при чтении индекса (защита от повреждённых данных)
+	maxWordLength = 1 << 16
+)
+
+// Множество анаграмм в индексе
+type Group struct {
+	// Первое встретившееся в словаре слово из множества
+	Key string `json:"key"`
+	// Слова множества, отсортированные по возрастанию
+	Words []string `json:"words"`
+}
+
+// Индекс анаграмм: отображение строки с отсортированными символами нормализованного слова (SortLetters) в множество
+// слов словаря
+type Index struct {
+	normalizer Normalizer
+	groups     map[string]*Group
+	// Сигнатуры в порядке появления множеств в словаре
+	order []string
+}
+
+// Построение индекса по словам (слова приводятся к нижнему регистру, повторы удаляются)
+func NewIndex(words []string) *Index {
+	return NewIndexNormalized(words, Normalizer{})
+}
+
+// Построение индекса по словам с нормализацией (в индексе хранится исходное написание слов в нижнем регистре)
+func NewIndexNormalized(words []string, normalizer Normalizer) *Index {
+	index := &Index{normalizer: normalizer, groups: make(map[string]*Group)}
+	for _, word := range words {
+		index.Add(word)
+	}
+	return index
+}
+
+// Получение правил нормализации индекса
+func (index *Index) Normalizer() Normalizer {
+	return index.normalizer
+}
+
+// Добавление слова в индекс (слово вставляется в множество с сохранением сортировки)
+func (index *Index) Add(word string) {
+	word = strings.ToLower(word)
+	signature := index.normalizer.Signature(word)
+	group, ok := index.groups[signature]
+	if !ok {
+		group = &Group{Key: word}
+		index.groups[signature] = group
+		index.order = append(index.order, signature)
+	}
+	i, found := slices.BinarySearch(group.Words, word)
+	if found {
+		return
+	}
+	group.Words = slices.Insert(group.Words, i, word)
+}
+
+// Построение индекса по словарю из io.Reader (одно слово в строке, пустые строки пропускаются)
+func BuildIndex(in io.Reader) (*Index, error) {
+	return BuildIndexNormalized(in, Normalizer{})
+}
+
+// Построение индекса с нормализацией по словарю из io.Reader
+func BuildIndexNormalized(in io.Reader, normalizer Normalizer) (*Index, error) {
+	index := NewIndexNormalized(nil, normalizer)
+	if err := index.AddWords(in); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// Добавление в индекс слов из io.Reader (одно слово в строке, пустые строки пропускаются)
+func (index *Index) AddWords(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" {
+			continue
+		}
+		index.Add(word)
+	}
+	return scanner.Err()
+}
+
+// Построение индекса по файлу словаря
+func BuildIndexFile(path string) (*Index, error) {
+	return BuildIndexFileNormalized(path, Normalizer{})
+}
+
+// Построение индекса с нормализацией по файлу словаря
+func BuildIndexFileNormalized(path string, normalizer Normalizer) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return BuildIndexNormalized(file, normalizer)
+}
+
+// Построение индекса с нормализацией по нескольким файлам словаря
+func BuildIndexFiles(paths []string, normalizer Normalizer) (*Index, error) {
+	index := NewIndexNormalized(nil, normalizer)
+	for _, path := range paths {
+		if err := index.addFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// Добавление в индекс слов из файла
+func (index *Index) addFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return index.AddWords(file)
+}
+
+// Получение всех слов словаря, являющихся анаграммами word (включая само слово, если оно есть в словаре)
+func (index *Index) Lookup(word string) []string {
+	group, ok := index.groups[index.normalizer.Signature(word)]
+	if !ok {
+		return nil
+	}
+	return slices.Clone(group.Words)
+}
+
+// Получение множества, в которое входят анаграммы word
+func (index *Index) Group(word string) (Group, bool) {
+	group, ok := index.groups[index.normalizer.Signature(word)]
+	if !ok {
+		return Group{}, false
+	}
+	return Group{Key: group.Key, Words: slices.Clone(group.Words)}, true
+}
+
+// Количество множеств в индексе (включая множества из одного элемента)
+func (index *Index) Len() int {
+	return len(index.groups)
+}
+
+// Получение множеств анаграмм в порядке их появления в словаре (множества меньше minSize пропускаются)
+func (index *Index) Groups(minSize int) []Group {
+	groups := make([]Group, 0, len(index.order))
+	for _, signature := range index.order {
+		group := index.groups[signature]
+		if len(group.Words) < minSize {
+			continue
+		}
+		groups = append(groups, Group{Key: group.Key, Words: slices.Clone(group.Words)})
+	}
+	return groups
+}
+
+// Построение map множеств анаграмм в формате AnagramsMap()
+func (index *Index) AnagramsMap() map[string][]string {
+	result := make(map[string][]string)
+	for _, group := range index.Groups(2) {
+		result[group.Key] = group.Words
+	}
+	return result
+}
+
+// Запись индекса в компактном бинарном формате: заголовок, правила нормализации (байт), число множеств, затем для
+// каждого множества (в порядке появления в словаре) число слов, позиция ключа среди слов и слова (длина + байты).
+// Все числа - uvarint
+func (index *Index) WriteTo(out io.Writer) (int64, error) {
+	writer := &countingWriter{writer: bufio.NewWriter(out)}
+	buffer := make([]byte, binary.MaxVarintLen64)
+	writeUvarint := func(value int) {
+		writer.Write(buffer[:binary.PutUvarint(buffer, uint64(value))])
+	}
+
+	writer.Write([]byte(indexMagic))
+	writer.Write([]byte{indexVersion, index.normalizer.flags()})
+	writeUvarint(len(index.order))
+	for _, signature := range index.order {
+		group := index.groups[signature]
+		keyPosition, _ := slices.BinarySearch(group.Words, group.Key)
+		writeUvarint(len(group.Words))
+		writeUvarint(keyPosition)
+		for _, word := range group.Words {
+			writeUvarint(len(word))
+			writer.Write([]byte(word))
+		}
+	}
+	if writer.err == nil {
+		writer.err = writer.writer.Flush()
+	}
+	return writer.count, writer.err
+}
+
+// Чтение индекса, записанного WriteTo()
+func ReadIndex(in io.Reader) (*Index, error) {
+	reader := bufio.NewReader(in)
+	header := make([]byte, len(indexMagic)+2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, ErrInvalidIndex
+	}
+	if string(header[:len(indexMagic)]) != indexMagic || header[len(indexMagic)] != indexVersion {
+		return nil, ErrInvalidIndex
+	}
+	normalizer, ok := normalizerFromFlags(header[len(indexMagic)+1])
+	if !ok {
+		return nil, ErrInvalidIndex
+	}
+
+	readUvarint := func() (int, error) {
+		value, err := binary.ReadUvarint(reader)
+		if err != nil || value > uint64(^uint32(0)) {
+			return 0, ErrInvalidIndex
+		}
+		return int(value), nil
+	}
+
+	groupsCount, err := readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{normalizer: normalizer, groups: make(map[string]*Group, min(groupsCount, 1<<20))}
+	for i := 0; i < groupsCount; i++ {
+		wordsCount, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		keyPosition, err := readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if wordsCount == 0 || keyPosition >= wordsCount {
+			return nil, ErrInvalidIndex
+		}
+		group := &Group{Words: make([]string, 0, min(wordsCount, 1<<10))}
+		for j := 0; j < wordsCount; j++ {
+			length, err := readUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if length > maxWordLength {
+				return nil, ErrInvalidIndex
+			}
+			word := make([]byte, length)
+			if _, err := io.ReadFull(reader, word); err != nil {
+				return nil, ErrInvalidIndex
+			}
+			group.Words = append(group.Words, string(word))
+		}
+		group.Key = group.Words[keyPosition]
+		signature := normalizer.Signature(group.Key)
+		if _, ok := index.groups[signature]; ok {
+			return nil, ErrInvalidIndex
+		}
+		// Все слова множества должны быть анаграммами ключа и идти по возрастанию
+		for j, word := range group.Words {
+			if normalizer.Signature(word) != signature || (j > 0 && group.Words[j-1] >= word) {
+				return nil, ErrInvalidIndex
+			}
+		}
+		index.groups[signature] = group
+		index.order = append(index.order, signature)
+	}
+	return index, nil
+}
+
+// Сохранение индекса в файл
+func (index *Index) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := index.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Загрузка индекса из файла
+func LoadIndexFile(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadIndex(file)
+}
+
+// Обёртка для io.Writer, подсчитывающая записанные байты и запоминающая первую ошибку
+type countingWriter struct {
+	writer *bufio.Writer
+	count  int64
+	err    error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.writer.Write(p)
+	w.count += int64(n)
+	w.err = err
+	return n, err
+}
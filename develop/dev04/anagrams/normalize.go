@@ -0,0 +1,110 @@
+package anagrams
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Форма нормализации Unicode
+type NormalizationForm uint8
+
+const (
+	// Без нормализации
+	NoNormalization NormalizationForm = iota
+	// Каноническая композиция ("е" + U+0308 -> "ё")
+	NFC
+	// Совместимая композиция (дополнительно "ﬁ" -> "fi", полноширинные символы -> обычные)
+	NFKC
+)
+
+// Правила приведения слов перед поиском анаграмм (нулевое значение - только приведение к нижнему регистру).
+// Нормализация влияет только на сравнение букв: в результат попадает исходное написание слова
+type Normalizer struct {
+	Form NormalizationForm
+	// Замена "ё" на "е"
+	FoldYo bool
+	// Удаление знаков препинания (дефисов, апострофов и т.д.) и пробельных символов
+	StripPunctuation bool
+	// Удаление диакритических знаков ("é" -> "e"), кроме "й" и "ё" - отдельных букв русского алфавита
+	StripDiacritics bool
+}
+
+// Замена "ё" (в том числе в разложенной форме) на "е"
+var yoReplacer *strings.Replacer = strings.NewReplacer("ё", "е", "ё", "е")
+
+// Приведение слова к виду, в котором сравниваются буквы
+func (normalizer Normalizer) Normalize(word string) string {
+	switch normalizer.Form {
+	case NFC:
+		word = norm.NFC.String(word)
+	case NFKC:
+		word = norm.NFKC.String(word)
+	}
+	word = strings.ToLower(word)
+	if normalizer.StripDiacritics {
+		word = stripDiacritics(word)
+	}
+	if normalizer.FoldYo {
+		word = yoReplacer.Replace(word)
+	}
+	if normalizer.StripPunctuation {
+		word = strings.Map(func(char rune) rune {
+			if unicode.IsPunct(char) || unicode.IsSpace(char) {
+				return -1
+			}
+			return char
+		}, word)
+	}
+	return word
+}
+
+// Получение сигнатуры слова (строки с отсортированными символами нормализованного слова)
+func (normalizer Normalizer) Signature(word string) string {
+	return SortLetters(normalizer.Normalize(word))
+}
+
+// Удаление диакритических знаков: буквы раскладываются (NFD) и из них удаляются комбинируемые знаки
+func stripDiacritics(word string) string {
+	var builder strings.Builder
+	for _, char := range norm.NFC.String(word) {
+		switch char {
+		case 'й', 'Й', 'ё', 'Ё':
+			builder.WriteRune(char)
+			continue
+		}
+		for _, part := range norm.NFD.String(string(char)) {
+			if !unicode.Is(unicode.Mn, part) {
+				builder.WriteRune(part)
+			}
+		}
+	}
+	return builder.String()
+}
+
+// Кодирование правил в один байт (для бинарного формата индекса)
+func (normalizer Normalizer) flags() byte {
+	flags := byte(normalizer.Form) & 0b11
+	if normalizer.FoldYo {
+		flags |= 1 << 2
+	}
+	if normalizer.StripPunctuation {
+		flags |= 1 << 3
+	}
+	if normalizer.StripDiacritics {
+		flags |= 1 << 4
+	}
+	return flags
+}
+
+// Декодирование правил из байта (ok == false при неизвестных битах)
+func normalizerFromFlags(flags byte) (Normalizer, bool) {
+	normalizer := Normalizer{
+		Form:             NormalizationForm(flags & 0b11),
+		FoldYo:           flags&(1<<2) != 0,
+		StripPunctuation: flags&(1<<3) != 0,
+		StripDiacritics:  flags&(1<<4) != 0,
+	}
+	return normalizer, flags < 1<<5 && normalizer.Form <= NFKC
+}
@@ -0,0 +1,80 @@
+package anagrams
+
+import (
+	"errors"
+	"flag"
+)
+
+var ErrNonPositiveMinSize error = errors.New("minimal group size must be a positive number")
+var ErrUnknownFormat error = errors.New("output format must be one of: text, json, csv")
+var ErrNoDictionary error = errors.New("HTTP service requires dictionary files")
+var ErrUnknownNormalization error = errors.New("normalization form must be one of: none, nfc, nfkc")
+
+// Форматы вывода
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+type Options struct {
+	Filepaths    []string
+	MinGroupSize int
+	Format       string
+	Query        string
+	Normalizer   Normalizer
+	Address      string
+}
+
+func NewOptions(filepaths []string, minGroupSize int, format, query string, normalizer Normalizer) Options {
+	return Options{
+		Filepaths:    filepaths,
+		MinGroupSize: minGroupSize,
+		Format:       format,
+		Query:        query,
+		Normalizer:   normalizer,
+	}
+}
+
+// Получение значений флагов и аргументов
+func ParseArguments(arguments []string) (Options, error) {
+	fSet := flag.NewFlagSet("anagrams", flag.ContinueOnError)
+	minGroupSize := fSet.Int("min", 2, "minimal size of printed group")
+	format := fSet.String("format", FormatText, "output format: text, json or csv")
+	query := fSet.String("q", "", "print only anagram group of the word")
+	form := fSet.String("norm", "none", "unicode normalization form: none, nfc or nfkc")
+	foldYo := fSet.Bool("yo", false, "treat \"ё\" as \"е\"")
+	stripPunctuation := fSet.Bool("punct", false, "ignore punctuation and whitespace in words")
+	stripDiacritics := fSet.Bool("diacritics", false, "ignore diacritical marks (except \"й\" and \"ё\")")
+	address := fSet.String("http", "", "run HTTP service on the address (e.g. localhost:8080)")
+	if err := fSet.Parse(arguments); err != nil {
+		return Options{}, err
+	}
+
+	if *minGroupSize < 1 {
+		return Options{}, ErrNonPositiveMinSize
+	}
+	switch *format {
+	case FormatText, FormatJSON, FormatCSV:
+	default:
+		return Options{}, ErrUnknownFormat
+	}
+
+	normalizer := Normalizer{FoldYo: *foldYo, StripPunctuation: *stripPunctuation, StripDiacritics: *stripDiacritics}
+	switch *form {
+	case "none":
+		normalizer.Form = NoNormalization
+	case "nfc":
+		normalizer.Form = NFC
+	case "nfkc":
+		normalizer.Form = NFKC
+	default:
+		return Options{}, ErrUnknownNormalization
+	}
+	options := NewOptions(fSet.Args(), *minGroupSize, *format, *query, normalizer)
+	options.Address = *address
+	if options.Address != "" && len(options.Filepaths) == 0 {
+		return Options{}, ErrNoDictionary
+	}
+	return options, nil
+}
@@ -0,0 +1,70 @@
+package anagrams
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+)
+
+var ErrWordNotFound error = errors.New("no anagrams found for the word")
+
+// Вывод множеств анаграмм в формате format:
+// text - "ключ: слово1 слово2 ..." в строке, json - объект {"ключ": ["слово1", ...]}, csv - "ключ,слово1,слово2,..."
+func WriteGroups(out io.Writer, groups []Group, format string) (err error) {
+	writer := bufio.NewWriter(out)
+	// Ошибка записи буферизированного вывода возвращается, если не было других ошибок
+	defer func() {
+		if flushErr := writer.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	switch format {
+	case FormatText:
+		for _, group := range groups {
+			if _, err := writer.WriteString(group.Key + ": " + strings.Join(group.Words, " ") + "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		// Ключи объекта сортируются encoding/json
+		result := make(map[string][]string, len(groups))
+		for _, group := range groups {
+			result[group.Key] = group.Words
+		}
+		return json.NewEncoder(writer).Encode(result)
+	case FormatCSV:
+		csvWriter := csv.NewWriter(writer)
+		for _, group := range groups {
+			if err := csvWriter.Write(append([]string{group.Key}, group.Words...)); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return ErrUnknownFormat
+}
+
+// Вывод результата: множества из не менее чем MinGroupSize слов, отсортированные по ключу, либо (если указано
+// слово Query) только множество этого слова
+func PrintAnagrams(index *Index, out io.Writer, options Options) error {
+	if options.Query != "" {
+		group, ok := index.Group(options.Query)
+		if !ok {
+			return ErrWordNotFound
+		}
+		return WriteGroups(out, []Group{group}, options.Format)
+	}
+
+	groups := index.Groups(options.MinGroupSize)
+	slices.SortFunc(groups, func(a, b Group) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return WriteGroups(out, groups, options.Format)
+}
@@ -0,0 +1,146 @@
+package anagrams
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Размер малого алфавита (строчные буквы русского и латинского алфавитов)
+const alphabetSize = 33 + 26
+
+// Первый байт сигнатуры CountSignature() - не встречается в UTF-8, поэтому сигнатура не совпадёт с результатом SortLetters()
+const countSignatureTag = 0xFF
+
+// Позиция буквы в малом алфавите (-1, если буквы в нём нет)
+func letterPosition(char rune) int {
+	switch {
+	case 'а' <= char && char <= 'я':
+		return int(char - 'а')
+	case char == 'ё':
+		return 32
+	case 'a' <= char && char <= 'z':
+		return 33 + int(char-'a')
+	}
+	return -1
+}
+
+// Получение сигнатуры слова по количеству букв: анаграммы имеют одинаковую сигнатуру. Для слов из букв малого
+// алфавита сигнатура строится без сортировки (пары "позиция буквы, количество"), для остальных - через SortLetters()
+func CountSignature(word string) string {
+	var counts [alphabetSize]uint8
+	// Множество встретившихся букв (позиция буквы - номер бита)
+	var used uint64
+	for _, char := range word {
+		position := letterPosition(char)
+		if position < 0 || counts[position] == 255 {
+			return SortLetters(word)
+		}
+		counts[position]++
+		used |= 1 << position
+	}
+	var signature [1 + 2*alphabetSize]byte
+	signature[0] = countSignatureTag
+	n := 1
+	for ; used != 0; used &= used - 1 {
+		position := bits.TrailingZeros64(used)
+		signature[n], signature[n+1] = byte(position), counts[position]
+		n += 2
+	}
+	return string(signature[:n])
+}
+
+// Слово с сигнатурой
+type signedWord struct {
+	word      string
+	signature string
+}
+
+// Параллельное построение map множества анаграмм (результат совпадает с AnagramsMap()). Слова распределяются между
+// workers обработчиками по сигнатуре, поэтому все анаграммы попадают к одному обработчику; порядок слов внутри
+// обработчика совпадает с порядком в словаре, поэтому ключом остаётся первое встретившееся слово.
+// Если workers <= 0 - используется runtime.GOMAXPROCS(0)
+func AnagramsMapParallel(words *[]string, workers int) *map[string][]string {
+	return AnagramsMapParallelNormalized(words, workers, Normalizer{})
+}
+
+// Параллельное построение map множества анаграмм с нормализацией слов (результат совпадает с AnagramsMapNormalized())
+func AnagramsMapParallelNormalized(words *[]string, workers int, normalizer Normalizer) *map[string][]string {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	n := len(*words)
+	chunkSize := (n + workers - 1) / workers
+	seed := maphash.MakeSeed()
+
+	// Этап 1: вычисление сигнатур по частям словаря и распределение слов по обработчикам
+	// (shards[часть][обработчик] - слова части в порядке словаря)
+	shards := make([][][]signedWord, workers)
+	wg := sync.WaitGroup{}
+	for chunk := 0; chunk < workers; chunk++ {
+		from, to := chunk*chunkSize, min((chunk+1)*chunkSize, n)
+		if from >= to {
+			break
+		}
+		wg.Add(1)
+		go func(chunk int, part []string) {
+			defer wg.Done()
+			local := make([][]signedWord, workers)
+			for _, word := range part {
+				word = strings.ToLower(word)
+				signature := CountSignature(normalizer.Normalize(word))
+				shard := int(maphash.String(seed, signature) % uint64(workers))
+				local[shard] = append(local[shard], signedWord{word, signature})
+			}
+			shards[chunk] = local
+		}(chunk, (*words)[from:to])
+	}
+	wg.Wait()
+
+	// Этап 2: построение множеств каждым обработчиком (части обходятся по порядку - порядок слов сохраняется)
+	partial := make([]map[string][]string, workers)
+	for shard := 0; shard < workers; shard++ {
+		wg.Add(1)
+		go func(shard int) {
+			defer wg.Done()
+			result := make(map[string][]string)
+			sortedWordKeyMap := make(map[string]string)
+			wordsSet := make(map[string]struct{})
+			for _, local := range shards {
+				if local == nil {
+					continue
+				}
+				for _, entry := range local[shard] {
+					if _, ok := wordsSet[entry.word]; ok {
+						continue
+					}
+					wordsSet[entry.word] = struct{}{}
+					if _, ok := sortedWordKeyMap[entry.signature]; !ok {
+						sortedWordKeyMap[entry.signature] = entry.word
+					}
+					key := sortedWordKeyMap[entry.signature]
+					result[key] = append(result[key], entry.word)
+				}
+			}
+			ClearAnagramsMap(result)
+			SortAnagramsMap(result)
+			partial[shard] = result
+		}(shard)
+	}
+	wg.Wait()
+
+	// Этап 3: объединение результатов (ключи разных обработчиков не пересекаются)
+	size := 0
+	for _, result := range partial {
+		size += len(result)
+	}
+	result := make(map[string][]string, size)
+	for _, shardResult := range partial {
+		for key, anagrams := range shardResult {
+			result[key] = anagrams
+		}
+	}
+	return &result
+}
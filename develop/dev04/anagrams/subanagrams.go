@@ -0,0 +1,199 @@
+package anagrams
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Максимальное количество результатов FindPhraseAnagrams() по умолчанию
+const DefaultPhraseLimit = 1000
+
+// Проверка, что буквы signature являются подмножеством (с учётом повторов) букв letters (обе строки - результат SortLetters())
+func isSubset(signature, letters string) bool {
+	if len(signature) > len(letters) {
+		return false
+	}
+	for signature != "" {
+		want, wantSize := utf8.DecodeRuneInString(signature)
+		// Пропуск букв letters, меньших очередной буквы signature
+		for {
+			if letters == "" {
+				return false
+			}
+			have, haveSize := utf8.DecodeRuneInString(letters)
+			letters = letters[haveSize:]
+			if have == want {
+				break
+			}
+			if have > want {
+				return false
+			}
+		}
+		signature = signature[wantSize:]
+	}
+	return true
+}
+
+// Нормализация набора букв по правилам индекса и удаление пробельных символов
+func (index *Index) normalizeLetters(letters string) string {
+	return strings.Map(func(char rune) rune {
+		if unicode.IsSpace(char) {
+			return -1
+		}
+		return char
+	}, index.normalizer.Normalize(letters))
+}
+
+// Получение множеств, слова которых можно составить из букв letters (каждая буква используется не больше раз, чем
+// встречается в letters)
+func (index *Index) subGroups(letters string) []string {
+	sortedLetters := SortLetters(letters)
+	signatures := []string{}
+	for _, signature := range index.order {
+		if signature != "" && isSubset(signature, sortedLetters) {
+			signatures = append(signatures, signature)
+		}
+	}
+	return signatures
+}
+
+// Поиск всех слов словаря, которые можно составить из набора букв letters (как в Scrabble), слова отсортированы
+func (index *Index) FindSubAnagrams(letters string) []string {
+	result := []string{}
+	for _, signature := range index.subGroups(index.normalizeLetters(letters)) {
+		result = append(result, index.groups[signature].Words...)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Кандидат для составления фразы: множество анаграмм и вектор количества букв его слов
+type phraseCandidate struct {
+	words  []string
+	counts []int
+	length int
+}
+
+// Поиск фраз из не более чем maxWords слов словаря, составленных из всех букв phrase (пробелы не учитываются).
+// Количество результатов ограничено limit (если limit <= 0 - DefaultPhraseLimit). Перестановки одних и тех же слов
+// не повторяются (более длинные слова идут первыми). Фразы отсортированы
+func (index *Index) FindPhraseAnagrams(phrase string, maxWords, limit int) []string {
+	if limit <= 0 {
+		limit = DefaultPhraseLimit
+	}
+	letters := []rune(index.normalizeLetters(phrase))
+	if len(letters) == 0 || maxWords <= 0 {
+		return []string{}
+	}
+
+	// Алфавит фразы: каждой букве сопоставляется позиция в векторе количества букв
+	alphabet := make(map[rune]int)
+	remaining := []int{}
+	for _, letter := range letters {
+		position, ok := alphabet[letter]
+		if !ok {
+			position = len(remaining)
+			alphabet[letter] = position
+			remaining = append(remaining, 0)
+		}
+		remaining[position]++
+	}
+
+	// Кандидаты - только множества, слова которых можно составить из букв фразы
+	candidates := []phraseCandidate{}
+	for _, signature := range index.subGroups(string(letters)) {
+		candidate := phraseCandidate{words: index.groups[signature].Words, counts: make([]int, len(remaining))}
+		for _, letter := range signature {
+			candidate.counts[alphabet[letter]]++
+			candidate.length++
+		}
+		candidates = append(candidates, candidate)
+	}
+	// Сортировка кандидатов по убыванию длины (для отсечения по длине)
+	slices.SortStableFunc(candidates, func(a, b phraseCandidate) int {
+		return b.length - a.length
+	})
+
+	result := []string{}
+	// Позиции выбранных кандидатов
+	chosen := make([]int, 0, maxWords)
+	var search func(from, left int)
+	search = func(from, left int) {
+		if len(result) >= limit {
+			return
+		}
+		// Все буквы использованы - добавление всех фраз из выбранных множеств
+		if left == 0 {
+			appendPhrases(&result, candidates, chosen, limit)
+			return
+		}
+		if len(chosen) == maxWords {
+			return
+		}
+		for i := from; i < len(candidates); i++ {
+			candidate := candidates[i]
+			// Отсечение: даже самыми длинными из оставшихся слов не набрать нужное количество букв
+			if candidate.length*(maxWords-len(chosen)) < left {
+				return
+			}
+			if candidate.length > left || !fits(candidate.counts, remaining) {
+				continue
+			}
+			for j, count := range candidate.counts {
+				remaining[j] -= count
+			}
+			chosen = append(chosen, i)
+			// Множество может использоваться повторно, но только начиная с текущего (без перестановок)
+			search(i, left-candidate.length)
+			chosen = chosen[:len(chosen)-1]
+			for j, count := range candidate.counts {
+				remaining[j] += count
+			}
+			if len(result) >= limit {
+				return
+			}
+		}
+	}
+	search(0, len(letters))
+
+	slices.Sort(result)
+	return result
+}
+
+// Проверка, что буквы кандидата есть среди оставшихся букв
+func fits(counts, remaining []int) bool {
+	for i, count := range counts {
+		if count > remaining[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Добавление фраз - всех сочетаний слов из выбранных множеств (не более limit результатов)
+func appendPhrases(result *[]string, candidates []phraseCandidate, chosen []int, limit int) {
+	words := make([]string, len(chosen))
+	var combine func(i, from int)
+	combine = func(i, from int) {
+		if len(*result) >= limit {
+			return
+		}
+		if i == len(chosen) {
+			*result = append(*result, strings.Join(words, " "))
+			return
+		}
+		// Если множество выбрано несколько раз подряд - слова из него идут по возрастанию (без перестановок)
+		start := 0
+		if i > 0 && chosen[i] == chosen[i-1] {
+			start = from
+		}
+		group := candidates[chosen[i]].words
+		for j := start; j < len(group); j++ {
+			words[i] = group[j]
+			combine(i+1, j)
+		}
+	}
+	combine(0, 0)
+}
@@ -1,3 +1,5 @@
 module dev04
 
 go 1.21.6
+
+require golang.org/x/text v0.14.0
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"dev04/anagrams"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDictionaryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dict.txt")
+	if err := os.WriteFile(path, []byte("пятак\nпятка\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dictionary, err := NewDictionary([]string{path}, anagrams.Normalizer{})
+	if err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	expected := anagrams.Group{Key: "пятак", Words: []string{"пятак", "пятка"}}
+	if got, err := dictionary.Anagrams("тяпка"); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v (%v), want %v", got, err, expected)
+	}
+
+	// После перезагрузки используется новое содержимое словаря
+	if err := os.WriteFile(path, []byte("пятак\nпятка\nтяпка\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.Reload(); err != nil {
+		t.Fatalf("error: got %v, want %v", err, nil)
+	}
+	expected.Words = append(expected.Words, "тяпка")
+	if got, err := dictionary.Anagrams("тяпка"); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v (%v), want %v", got, err, expected)
+	}
+
+	// При ошибке загрузки остаётся прежний индекс
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.Reload(); err == nil {
+		t.Errorf("error: got %v, want non-nil", err)
+	}
+	if got, err := dictionary.Anagrams("тяпка"); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %v (%v), want %v", got, err, expected)
+	}
+	if _, err := dictionary.Anagrams("кот"); err != anagrams.ErrWordNotFound {
+		t.Errorf("error: got %v, want %v", err, anagrams.ErrWordNotFound)
+	}
+}
+
+func TestNewDictionaryMissingFile(t *testing.T) {
+	if _, err := NewDictionary([]string{filepath.Join(t.TempDir(), "missing.txt")}, anagrams.Normalizer{}); err == nil {
+		t.Errorf("error: got %v, want non-nil", err)
+	}
+}
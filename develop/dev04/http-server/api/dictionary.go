@@ -0,0 +1,79 @@
+package api
+
+import (
+	"dev04/anagrams"
+	"sync"
+)
+
+// Словарь анаграмм, загружаемый из файлов; индекс может быть перезагружен во время работы сервиса
+type Dictionary struct {
+	mutex      sync.RWMutex
+	index      *anagrams.Index
+	paths      []string
+	normalizer anagrams.Normalizer
+}
+
+// Функция создания словаря с загрузкой индекса из файлов
+func NewDictionary(paths []string, normalizer anagrams.Normalizer) (*Dictionary, error) {
+	dictionary := &Dictionary{paths: paths, normalizer: normalizer}
+	if err := dictionary.Reload(); err != nil {
+		return nil, err
+	}
+	return dictionary, nil
+}
+
+// Функция создания словаря по уже построенному индексу (без возможности перезагрузки)
+func NewDictionaryFromIndex(index *anagrams.Index) *Dictionary {
+	return &Dictionary{index: index, normalizer: index.Normalizer()}
+}
+
+// Функция перезагрузки словаря: новый индекс строится полностью, после чего заменяет текущий
+// (при ошибке продолжает использоваться прежний индекс)
+func (dictionary *Dictionary) Reload() error {
+	if dictionary.paths == nil {
+		return nil
+	}
+	index, err := anagrams.BuildIndexFiles(dictionary.paths, dictionary.normalizer)
+	if err != nil {
+		return err
+	}
+	dictionary.mutex.Lock()
+	dictionary.index = index
+	dictionary.mutex.Unlock()
+	return nil
+}
+
+// Получение текущего индекса
+func (dictionary *Dictionary) current() *anagrams.Index {
+	dictionary.mutex.RLock()
+	defer dictionary.mutex.RUnlock()
+	return dictionary.index
+}
+
+// Количество множеств анаграмм в словаре
+func (dictionary *Dictionary) Len() int {
+	return dictionary.current().Len()
+}
+
+// Функция получения множества анаграмм слова
+func (dictionary *Dictionary) Anagrams(word string) (anagrams.Group, error) {
+	group, ok := dictionary.current().Group(word)
+	if !ok {
+		return anagrams.Group{}, anagrams.ErrWordNotFound
+	}
+	return group, nil
+}
+
+// Функция группировки переданных слов в множества анаграмм (по правилам нормализации словаря)
+func (dictionary *Dictionary) Groups(words []string) map[string][]string {
+	return *anagrams.AnagramsMapNormalized(&words, dictionary.normalizer)
+}
+
+// Функция получения слов словаря, составленных из букв letters
+func (dictionary *Dictionary) SubAnagrams(letters string) []string {
+	result := dictionary.current().FindSubAnagrams(letters)
+	if result == nil {
+		return []string{}
+	}
+	return result
+}
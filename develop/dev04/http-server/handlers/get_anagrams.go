@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"dev04/http-server/api"
+	"net/http"
+)
+
+// Обработчик получения множества анаграмм слова из словаря
+func GetAnagrams(dictionary *api.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Если http-метод не GET - ошибка
+		if r.Method != http.MethodGet {
+			ResponseJSON(w, ErrWrongRequestMethod.Error(), http.StatusBadRequest)
+			return
+		}
+
+		word, err := queryParameter(r, "word")
+		if err != nil {
+			ResponseJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		group, err := dictionary.Anagrams(word)
+		if err != nil {
+			ResponseJSON(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		ResponseJSON(w, group, http.StatusOK)
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"dev04/http-server/api"
+	"net/http"
+)
+
+// Обработчик получения слов словаря, составленных из заданного набора букв
+func GetSubAnagrams(dictionary *api.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Если http-метод не GET - ошибка
+		if r.Method != http.MethodGet {
+			ResponseJSON(w, ErrWrongRequestMethod.Error(), http.StatusBadRequest)
+			return
+		}
+
+		letters, err := queryParameter(r, "letters")
+		if err != nil {
+			ResponseJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ResponseJSON(w, dictionary.SubAnagrams(letters), http.StatusOK)
+	}
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import "errors"
+
+// Ограничение размера тела запроса
+const MaxBodySize = 1 << 20
+
+// Ограничение длины слова и набора букв в параметрах запроса
+const MaxWordLength = 256
+
+var ErrWrongRequestMethod error = errors.New("wrong request method")
+var ErrEmptyParameter error = errors.New("parameter cannot be empty")
+var ErrParameterTooLong error = errors.New("parameter is too long")
+var ErrBodyTooLarge error = errors.New("request body is too large")
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"dev04/anagrams"
+	"dev04/http-server/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestDictionary() *api.Dictionary {
+	return api.NewDictionaryFromIndex(anagrams.NewIndex([]string{"пятак", "пятка", "тяпка", "кот", "ток", "о"}))
+}
+
+func TestGetAnagramsHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		query          string
+		expectedOutput string
+		expectedStatus int
+	}{
+		{
+			name:           "Default request",
+			method:         "GET",
+			query:          "word=%D0%A2%D1%8F%D0%BF%D0%BA%D0%B0",
+			expectedOutput: "{\"result\":{\"key\":\"пятак\",\"words\":[\"пятак\",\"пятка\",\"тяпка\"]}}\n",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "Not found",
+			method:         "GET",
+			query:          "word=собака",
+			expectedOutput: "{\"error\":\"no anagrams found for the word\"}\n",
+			expectedStatus: http.StatusNotFound,
+		}, {
+			name:           "No word",
+			method:         "GET",
+			query:          "",
+			expectedOutput: "{\"error\":\"parameter cannot be empty: word\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		}, {
+			name:           "Too long word",
+			method:         "GET",
+			query:          "word=" + strings.Repeat("а", MaxWordLength+1),
+			expectedOutput: "{\"error\":\"parameter is too long: word\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		}, {
+			name:           "Wrong method",
+			method:         "POST",
+			query:          "word=пятак",
+			expectedOutput: "{\"error\":\"wrong request method\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, "/anagrams?"+testCase.query, nil)
+			responseRecorder := httptest.NewRecorder()
+			GetAnagrams(newTestDictionary()).ServeHTTP(responseRecorder, request)
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("status: got %v want %v", responseRecorder.Code, testCase.expectedStatus)
+			}
+			if responseRecorder.Body.String() != testCase.expectedOutput {
+				t.Errorf("result: got %v want %v", responseRecorder.Body.String(), testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestPostGroupsHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		method         string
+		body           string
+		expectedOutput string
+		expectedStatus int
+	}{
+		{
+			name:           "Default request",
+			method:         "POST",
+			body:           `{"words": ["Листок", "слиток", "кот", "ток", "дом"]}`,
+			expectedOutput: "{\"result\":{\"кот\":[\"кот\",\"ток\"],\"листок\":[\"листок\",\"слиток\"]}}\n",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "Empty list",
+			method:         "POST",
+			body:           `{"words": []}`,
+			expectedOutput: "{\"result\":{}}\n",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "Invalid JSON",
+			method:         "POST",
+			body:           `["кот"]`,
+			expectedOutput: "{\"error\":\"json: cannot unmarshal array into Go value of type handlers.groupsRequest\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		}, {
+			name:           "Too large body",
+			method:         "POST",
+			body:           `{"words": ["` + strings.Repeat("а", MaxBodySize) + `"]}`,
+			expectedOutput: "{\"error\":\"request body is too large\"}\n",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		}, {
+			name:           "Wrong method",
+			method:         "GET",
+			body:           `{"words": []}`,
+			expectedOutput: "{\"error\":\"wrong request method\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(testCase.method, "/groups", strings.NewReader(testCase.body))
+			request.Header.Set("Content-Type", "application/json")
+			responseRecorder := httptest.NewRecorder()
+			PostGroups(newTestDictionary()).ServeHTTP(responseRecorder, request)
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("status: got %v want %v", responseRecorder.Code, testCase.expectedStatus)
+			}
+			if responseRecorder.Body.String() != testCase.expectedOutput {
+				t.Errorf("result: got %v want %v", responseRecorder.Body.String(), testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestGetSubAnagramsHandler(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedOutput string
+		expectedStatus int
+	}{
+		{
+			name:           "Default request",
+			query:          "letters=окт",
+			expectedOutput: "{\"result\":[\"кот\",\"о\",\"ток\"]}\n",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "Nothing found",
+			query:          "letters=ы",
+			expectedOutput: "{\"result\":[]}\n",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "No letters",
+			query:          "",
+			expectedOutput: "{\"error\":\"parameter cannot be empty: letters\"}\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/subanagrams?"+testCase.query, nil)
+			responseRecorder := httptest.NewRecorder()
+			GetSubAnagrams(newTestDictionary()).ServeHTTP(responseRecorder, request)
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("status: got %v want %v", responseRecorder.Code, testCase.expectedStatus)
+			}
+			if responseRecorder.Body.String() != testCase.expectedOutput {
+				t.Errorf("result: got %v want %v", responseRecorder.Body.String(), testCase.expectedOutput)
+			}
+		})
+	}
+}
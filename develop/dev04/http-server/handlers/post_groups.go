@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"dev04/http-server/api"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Тело запроса группировки слов
+type groupsRequest struct {
+	Words []string `json:"words"`
+}
+
+// Обработчик группировки переданного списка слов в множества анаграмм
+func PostGroups(dictionary *api.Dictionary) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Если http-метод не POST - ошибка
+		if r.Method != http.MethodPost {
+			ResponseJSON(w, ErrWrongRequestMethod.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Парсинг тела запроса с ограничением размера
+		var request groupsRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				ResponseJSON(w, ErrBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			ResponseJSON(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ResponseJSON(w, dictionary.Groups(request.Words), http.StatusOK)
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Получение обязательного параметра запроса с проверкой длины
+func queryParameter(r *http.Request, name string) (string, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return "", fmt.Errorf("%w: %s", ErrEmptyParameter, name)
+	}
+	if utf8.RuneCountInString(value) > MaxWordLength {
+		return "", fmt.Errorf("%w: %s", ErrParameterTooLong, name)
+	}
+	return value, nil
+}
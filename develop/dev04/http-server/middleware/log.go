@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"time"
+)
+
+// Функция логгер обрабатываемых http-запросов
+func Logger(process http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		// Обёртка для http.ResponseWriter для получения результата обработки запроса
+		stealer := NewResponseStealer(w)
+		process.ServeHTTP(stealer, r)
+		level := "[WARN]"
+		switch {
+		case stealer.StatusCode < 400:
+			level = "[INFO]"
+		case stealer.StatusCode < 500:
+			level = "[ERROR]"
+		}
+		log.Printf("%s %s %s time - %v status - %d", level, r.Method, r.URL.Path, time.Since(now), stealer.StatusCode)
+	})
+}
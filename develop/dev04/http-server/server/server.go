@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"dev04/anagrams"
+	"dev04/http-server/api"
+	"dev04/http-server/handlers"
+	"dev04/http-server/middleware"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Ограничение размера заголовков запроса (включая строку запроса)
+const maxHeaderBytes = 1 << 16
+
+// Создание маршрутизатора http-запросов
+func newMux(dictionary *api.Dictionary) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/anagrams", handlers.GetAnagrams(dictionary))
+	mux.Handle("/groups", handlers.PostGroups(dictionary))
+	mux.Handle("/subanagrams", handlers.GetSubAnagrams(dictionary))
+	// Оборачивание маршрутизатора в функцию-логгер (декоратор)
+	return middleware.Logger(mux)
+}
+
+// Инициализация и запуск сервера
+func StartServer(address string, paths []string, normalizer anagrams.Normalizer) error {
+	dictionary, err := api.NewDictionary(paths, normalizer)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] dictionary loaded: %d groups", dictionary.Len())
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           newMux(dictionary),
+		MaxHeaderBytes:    maxHeaderBytes,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Запуск сервера (ошибка запуска завершает ожидание сигналов)
+	serverErrors := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+	if err := UntilInterrupt(dictionary, serverErrors); err != nil {
+		return err
+	}
+
+	// Корректное выключение сервера
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Ожидание сигнала о завершении работы программы или ошибки сервера из serverErrors;
+// по сигналу SIGHUP словарь перезагружается
+func UntilInterrupt(dictionary *api.Dictionary, serverErrors <-chan error) error {
+	c := make(chan os.Signal, 1)
+	defer signal.Stop(c)
+	signal.Notify(c, os.Interrupt, syscall.SIGHUP)
+	for {
+		var received os.Signal
+		select {
+		case err := <-serverErrors:
+			return err
+		case received = <-c:
+		}
+		if received != syscall.SIGHUP {
+			return nil
+		}
+		if err := dictionary.Reload(); err != nil {
+			log.Printf("[ERROR] dictionary reload failed: %v", err)
+			continue
+		}
+		log.Printf("[INFO] dictionary reloaded: %d groups", dictionary.Len())
+	}
+}
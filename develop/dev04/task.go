@@ -2,7 +2,9 @@ package main
 
 import (
 	"dev04/anagrams"
+	"dev04/http-server/server"
 	"fmt"
+	"os"
 )
 
 /*
@@ -22,9 +24,45 @@ import (
 В результате каждое слово должно встречаться только один раз.
 
 Программа должна проходить все тесты. Код должен проходить проверки go vet и golint.
+
+Утилита читает словарь (одно слово в строке) из файлов-аргументов или из STDIN и поддерживает флаги:
+-min — минимальный размер выводимого множества (по умолчанию 2)
+-format — формат вывода: text, json или csv
+-q — вывести только множество анаграмм указанного слова
+-norm, -yo, -punct, -diacritics — нормализация слов (NFC/NFKC, "ё" как "е", без пунктуации, без диакритики)
+-http — запустить HTTP-сервис по указанному адресу (словарь перезагружается по сигналу SIGHUP):
+	GET /anagrams?word= — множество анаграмм слова
+	POST /groups — множества анаграмм среди слов из тела запроса {"words": [...]}
+	GET /subanagrams?letters= — слова словаря, составленные из букв letters
 */
 
 func main() {
-	a := []string{"пятак", "пятка", "тяпка", "листок", "слиток", "столик"}
-	fmt.Println(*anagrams.AnagramsMap(&a))
+	options, err := anagrams.ParseArguments(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	// Режим HTTP-сервиса: словарь загружается из файлов и перезагружается по SIGHUP
+	if options.Address != "" {
+		if err := server.StartServer(options.Address, options.Filepaths, options.Normalizer); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return
+	}
+
+	var index *anagrams.Index
+	if len(options.Filepaths) == 0 {
+		index, err = anagrams.BuildIndexNormalized(os.Stdin, options.Normalizer)
+	} else {
+		index, err = anagrams.BuildIndexFiles(options.Filepaths, options.Normalizer)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if err := anagrams.PrintAnagrams(index, os.Stdout, options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
@@ -1,3 +1,8 @@
 module dev05
 
-go 1.21.6
+go 1.22
+
+require (
+	github.com/dlclark/regexp2 v1.11.5
+	github.com/klauspost/compress v1.18.0
+)
@@ -0,0 +1,216 @@
+package grep
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Автомат Ахо-Корасик для поиска множества фиксированных строк за время, линейное от длины текста
+// (независимо от количества строк); совпадения ищутся по правилу "самое левое, затем самое длинное"
+type AhoCorasick struct {
+	// Переходы по ASCII-символам из корня (корень используется чаще остальных состояний)
+	rootASCII [utf8.RuneSelf]int32
+	// Переходы бора: для каждого состояния - символ -> состояние
+	next []map[rune]int32
+	// Суффиксные ссылки
+	fail []int32
+	// Длина (в символах) самой длинной строки, оканчивающейся в состоянии (0 - нет)
+	output []int32
+	// Глубина состояния в боре (длина соответствующего префикса в символах)
+	depth []int32
+	// Ссылки на ближайшее по цепочке суффиксных ссылок состояние, в котором заканчивается строка (0 - нет)
+	dictionary []int32
+	// Длина самой длинной строки в символах
+	maxLength int
+	// Есть ли среди строк пустая (пустая строка подходит под любую строку текста)
+	hasEmpty   bool
+	ignoreCase bool
+}
+
+// Построение автомата по набору строк
+func NewAhoCorasick(patterns []string, ignoreCase bool) *AhoCorasick {
+	automaton := &AhoCorasick{
+		next:       []map[rune]int32{{}},
+		fail:       []int32{0},
+		output:     []int32{0},
+		depth:      []int32{0},
+		dictionary: []int32{0},
+		ignoreCase: ignoreCase,
+	}
+
+	// Построение бора
+	for _, pattern := range patterns {
+		if pattern == "" {
+			automaton.hasEmpty = true
+			continue
+		}
+		state := int32(0)
+		length := 0
+		for _, r := range pattern {
+			r = automaton.fold(r)
+			child, ok := automaton.next[state][r]
+			if !ok {
+				child = int32(len(automaton.next))
+				automaton.next = append(automaton.next, map[rune]int32{})
+				automaton.fail = append(automaton.fail, 0)
+				automaton.output = append(automaton.output, 0)
+				automaton.depth = append(automaton.depth, int32(length+1))
+				automaton.dictionary = append(automaton.dictionary, 0)
+				automaton.next[state][r] = child
+			}
+			state = child
+			length++
+		}
+		automaton.output[state] = int32(length)
+		automaton.maxLength = max(automaton.maxLength, length)
+	}
+
+	// Построение суффиксных ссылок обходом в ширину
+	queue := make([]int32, 0, len(automaton.next))
+	for _, child := range automaton.next[0] {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for r, child := range automaton.next[state] {
+			fail := automaton.fail[state]
+			for {
+				if target, ok := automaton.next[fail][r]; ok {
+					automaton.fail[child] = target
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = automaton.fail[fail]
+			}
+			// Если в состоянии строка не заканчивается - самая длинная строка берётся по суффиксной ссылке
+			if automaton.output[child] == 0 {
+				automaton.output[child] = automaton.output[automaton.fail[child]]
+			}
+			if fail := automaton.fail[child]; automaton.terminal(fail) {
+				automaton.dictionary[child] = fail
+			} else {
+				automaton.dictionary[child] = automaton.dictionary[fail]
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	for r := rune(0); r < utf8.RuneSelf; r++ {
+		automaton.rootASCII[r] = automaton.next[0][r]
+	}
+	return automaton
+}
+
+// Приведение символа к единому регистру (наименьший символ из класса эквивалентности unicode.SimpleFold)
+func (automaton *AhoCorasick) fold(r rune) rune {
+	if !automaton.ignoreCase {
+		return r
+	}
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		return r
+	}
+	folded := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		folded = min(folded, f)
+	}
+	return folded
+}
+
+// Заканчивается ли в состоянии одна из строк (у корня строк нет: пустая строка учитывается отдельно)
+func (automaton *AhoCorasick) terminal(state int32) bool {
+	return state != 0 && automaton.output[state] == automaton.depth[state]
+}
+
+// Переход автомата по символу
+func (automaton *AhoCorasick) step(state int32, r rune) int32 {
+	for {
+		if state == 0 {
+			if r < utf8.RuneSelf {
+				return automaton.rootASCII[r]
+			}
+			return automaton.next[0][r]
+		}
+		if target, ok := automaton.next[state][r]; ok {
+			return target
+		}
+		state = automaton.fail[state]
+	}
+}
+
+// Проверка наличия в строке хотя бы одной из строк автомата
+func (automaton *AhoCorasick) MatchString(s string) bool {
+	if automaton.hasEmpty {
+		return true
+	}
+	state := int32(0)
+	for _, r := range s {
+		state = automaton.step(state, automaton.fold(r))
+		if automaton.output[state] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Позиции (в байтах) n первых непересекающихся совпадений (n < 0 - всех). Текст просматривается один раз:
+// для каждого начального символа запоминается конец самого длинного совпадения, и совпадение выбирается,
+// как только ни одна строка не может начаться на этом символе или левее (глубина текущего состояния -
+// длина самого длинного суффикса текста, который ещё может продолжиться до совпадения)
+func (automaton *AhoCorasick) FindAllStringIndex(s string, n int) [][]int {
+	var result [][]int
+	// Для последних maxLength+2 символов: байтовые смещения начала и номер символа после конца самого
+	// длинного совпадения, начинающегося с символа (0 - нет)
+	window := automaton.maxLength + 2
+	starts := make([]int, window)
+	ends := make([]int, window)
+	// Номер символа, с которого начинается поиск следующего совпадения
+	next := 0
+	// Выбор совпадений, начинающихся левее символа frontier (дальше ни одно из них не продлится)
+	choose := func(frontier int) bool {
+		for next < frontier {
+			if end := ends[next%window]; end != 0 {
+				result = append(result, []int{starts[next%window], starts[end%window]})
+				if n >= 0 && len(result) >= n {
+					return false
+				}
+				next = end
+				continue
+			}
+			next++
+		}
+		return true
+	}
+
+	state := int32(0)
+	count := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		ends[count%window] = 0
+		count++
+		starts[count%window] = i
+		state = automaton.step(state, automaton.fold(r))
+		// Строки, заканчивающиеся на этом символе, - от самой длинной к самой короткой
+		match := state
+		if !automaton.terminal(match) {
+			match = automaton.dictionary[match]
+		}
+		for ; match != 0; match = automaton.dictionary[match] {
+			ends[(count-int(automaton.depth[match]))%window] = count
+		}
+		if !choose(count - int(automaton.depth[state])) {
+			return result
+		}
+	}
+	choose(count)
+	if result == nil && automaton.hasEmpty {
+		return [][]int{{0, 0}}
+	}
+	return result
+}
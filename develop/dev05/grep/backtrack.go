@@ -0,0 +1,121 @@
+package grep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dlclark/regexp2"
+)
+
+var ErrBudgetExceeded error = errors.New("-P matching time limit exceeded")
+
+// Matcher на основе движка регулярных выражений с возвратами (-P): поддерживает обратные ссылки,
+// просмотр вперёд и назад, атомарные группы. Поиск в одной строке ограничен по времени, чтобы
+// "катастрофические" шаблоны не подвешивали утилиту
+type BacktrackMatcher struct {
+	regexp *regexp2.Regexp
+}
+
+// Компиляция шаблона с ограничением времени поиска timeout
+func NewBacktrackMatcher(pattern string, timeout time.Duration) (*BacktrackMatcher, error) {
+	compiled, err := regexp2.Compile(pattern, regexp2.None)
+	if err != nil {
+		return nil, err
+	}
+	compiled.MatchTimeout = timeout
+	return &BacktrackMatcher{regexp: compiled}, nil
+}
+
+// Проверка наличия совпадения (при превышении ограничения времени совпадения нет)
+func (matcher *BacktrackMatcher) MatchString(s string) bool {
+	match, _ := matcher.MatchStringLimited(s)
+	return match
+}
+
+// Позиции совпадений (при превышении ограничения времени - найденные до этого)
+func (matcher *BacktrackMatcher) FindAllStringIndex(s string, n int) [][]int {
+	result, _ := matcher.FindAllStringIndexLimited(s, n)
+	return result
+}
+
+// Проверка наличия совпадения с ошибкой ErrBudgetExceeded при превышении ограничения времени
+func (matcher *BacktrackMatcher) MatchStringLimited(s string) (bool, error) {
+	match, err := matcher.regexp.MatchString(s)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrBudgetExceeded, err)
+	}
+	return match, nil
+}
+
+// Позиции совпадений в байтах с ошибкой ErrBudgetExceeded при превышении ограничения времени
+func (matcher *BacktrackMatcher) FindAllStringIndexLimited(s string, n int) ([][]int, error) {
+	return matcher.findAll(s, n, false)
+}
+
+// Позиции совпадений и групп захвата (при превышении ограничения времени - найденные до этого)
+func (matcher *BacktrackMatcher) FindAllStringSubmatchIndex(s string, n int) [][]int {
+	result, _ := matcher.FindAllStringSubmatchIndexLimited(s, n)
+	return result
+}
+
+// Позиции совпадений и групп захвата в байтах с ошибкой ErrBudgetExceeded при превышении ограничения времени
+func (matcher *BacktrackMatcher) FindAllStringSubmatchIndexLimited(s string, n int) ([][]int, error) {
+	return matcher.findAll(s, n, true)
+}
+
+// Имена групп в порядке их номеров (у групп без имени - пустая строка)
+func (matcher *BacktrackMatcher) SubexpNames() []string {
+	numbers := matcher.regexp.GetGroupNumbers()
+	names := make([]string, len(numbers))
+	for i, number := range numbers {
+		// Для групп без имени regexp2 возвращает номер группы
+		if name := matcher.regexp.GroupNameFromNumber(number); name != strconv.Itoa(number) {
+			names[i] = name
+		}
+	}
+	return names
+}
+
+// Поиск n первых совпадений (n < 0 - всех), с groups - вместе с позициями групп захвата
+func (matcher *BacktrackMatcher) findAll(s string, n int, groups bool) ([][]int, error) {
+	var result [][]int
+	// regexp2 возвращает позиции в символах - перевод в байты
+	var offsets []int
+	if utf8.RuneCountInString(s) != len(s) {
+		offsets = make([]int, 0, len(s)+1)
+		for i := range s {
+			offsets = append(offsets, i)
+		}
+		offsets = append(offsets, len(s))
+	}
+	byteOffset := func(index int) int {
+		if offsets == nil {
+			return index
+		}
+		return offsets[index]
+	}
+
+	match, err := matcher.regexp.FindStringMatch(s)
+	for ; match != nil && err == nil && (n < 0 || len(result) < n); match, err = matcher.regexp.FindNextMatch(match) {
+		if !groups {
+			result = append(result, []int{byteOffset(match.Index), byteOffset(match.Index + match.Length)})
+			continue
+		}
+		span := make([]int, 0, 2*match.GroupCount())
+		for _, group := range match.Groups() {
+			if len(group.Captures) == 0 {
+				span = append(span, -1, -1)
+				continue
+			}
+			span = append(span, byteOffset(group.Index), byteOffset(group.Index+group.Length))
+		}
+		result = append(result, span)
+	}
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrBudgetExceeded, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Формат сжатия: сигнатура в начале данных и функция создания распаковщика
+type compression struct {
+	magic     []byte
+	newReader func(in io.Reader) (io.ReadCloser, error)
+}
+
+// Поддерживаемые форматы сжатия (у bzip2 за сигнатурой "BZh" следует размер блока от 1 до 9)
+var compressions = func() []compression {
+	list := []compression{
+		{magic: []byte{0x1f, 0x8b}, newReader: newGzipReader},
+		{magic: []byte{0x28, 0xb5, 0x2f, 0xfd}, newReader: newZstdReader},
+	}
+	for level := byte('1'); level <= '9'; level++ {
+		list = append(list, compression{magic: []byte{'B', 'Z', 'h', level}, newReader: newBzip2Reader})
+	}
+	return list
+}()
+
+func newGzipReader(in io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(in)
+}
+
+func newBzip2Reader(in io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(bzip2.NewReader(in)), nil
+}
+
+func newZstdReader(in io.Reader) (io.ReadCloser, error) {
+	// Без параллельного декодирования распаковщик не запускает фоновых горутин
+	decoder, err := zstd.NewReader(in, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// Определение формата сжатия по сигнатуре в начале данных (nil - данные не сжаты). Байты дочитываются,
+// только пока прочитанное начало совпадает с началом одной из сигнатур, чтобы не блокировать потоковое чтение
+func detectCompression(reader *bufio.Reader) *compression {
+	reader.Peek(1)
+	for {
+		head, _ := reader.Peek(reader.Buffered())
+		candidate := false
+		for i := range compressions {
+			if bytes.HasPrefix(head, compressions[i].magic) {
+				return &compressions[i]
+			}
+			candidate = candidate || bytes.HasPrefix(compressions[i].magic, head)
+		}
+		if !candidate {
+			return nil
+		}
+		if _, err := reader.Peek(len(head) + 1); err != nil {
+			return nil
+		}
+	}
+}
+
+// Распаковка данных, если они сжаты gzip, bzip2 или zstd (иначе данные читаются как есть)
+func decompress(in io.Reader) (io.ReadCloser, error) {
+	reader := bufio.NewReaderSize(in, binaryPeekSize)
+	format := detectCompression(reader)
+	if format == nil {
+		return io.NopCloser(reader), nil
+	}
+	return format.newReader(reader)
+}
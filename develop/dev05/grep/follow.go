@@ -0,0 +1,121 @@
+package grep
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+var ErrFollowFiles error = errors.New("--follow requires exactly one file")
+
+// Интервал проверки появления новых данных в файле для --follow
+const followInterval = 250 * time.Millisecond
+
+// Количество первых байт файла, по которым определяется его перезапись с начала
+const followHeadSize = 256
+
+// Чтение растущего файла (как tail -F): в конце файла чтение не завершается, а ожидает новых данных.
+// Если по пути path появился другой файл (сменился inode - ротация логов), после дочитывания старого
+// файла открывается новый; если файл стал короче прочитанного или изменились его первые байты (усечение,
+// в том числе с последующей записью дальше прочитанного между проверками), чтение начинается сначала
+type followReader struct {
+	file     *os.File
+	path     string
+	interval time.Duration
+	// Прочитано байт текущего файла
+	offset int64
+	// Первые прочитанные байты текущего файла (не больше followHeadSize)
+	head []byte
+	// Файл был прочитан до конца: перед следующим чтением проверяется ротация и усечение
+	polled bool
+	// Закрытие канала завершает чтение (nil - чтение продолжается бесконечно)
+	done <-chan struct{}
+}
+
+func newFollowReader(file *os.File, path string, interval time.Duration, done <-chan struct{}) *followReader {
+	return &followReader{file: file, path: path, interval: interval, done: done}
+}
+
+func (reader *followReader) Read(buffer []byte) (int, error) {
+	for {
+		// Пока чтение ожидало, файл мог быть заменён или усечён и дописан - данные с текущей позиции
+		// читаются только после проверки
+		if reader.polled {
+			reader.polled = false
+			if _, err := reader.reopen(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := reader.file.Read(buffer)
+		if len(reader.head) < followHeadSize {
+			reader.head = append(reader.head, buffer[:min(n, followHeadSize-len(reader.head))]...)
+		}
+		reader.offset += int64(n)
+		if n > 0 || (err != nil && err != io.EOF) {
+			return n, err
+		}
+		switched, err := reader.reopen()
+		if err != nil {
+			return 0, err
+		}
+		if switched {
+			continue
+		}
+		reader.polled = true
+		select {
+		case <-reader.done:
+			return 0, io.EOF
+		case <-time.After(reader.interval):
+		}
+	}
+}
+
+// Проверка ротации и усечения файла после чтения до конца; возвращает, нужно ли продолжить чтение сразу
+func (reader *followReader) reopen() (bool, error) {
+	// Во время ротации файла по пути может временно не быть
+	info, err := os.Stat(reader.path)
+	if err != nil {
+		return false, nil
+	}
+	current, err := reader.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	switch {
+	// Данные, дописанные в старый файл до ротации, дочитываются перед переходом к новому
+	case !os.SameFile(info, current) && current.Size() > reader.offset:
+		return true, nil
+	case !os.SameFile(info, current):
+		file, err := os.Open(reader.path)
+		if err != nil {
+			return false, nil
+		}
+		reader.file.Close()
+		reader.file = file
+		reader.offset, reader.head = 0, reader.head[:0]
+		return true, nil
+	case info.Size() < reader.offset || reader.rewritten():
+		if _, err := reader.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		reader.offset, reader.head = 0, reader.head[:0]
+		return true, nil
+	}
+	return false, nil
+}
+
+// Проверка, изменились ли уже прочитанные первые байты файла (файл перезаписан с начала)
+func (reader *followReader) rewritten() bool {
+	head := make([]byte, len(reader.head))
+	n, err := reader.file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return !bytes.Equal(head[:n], reader.head)
+}
+
+func (reader *followReader) Close() error {
+	return reader.file.Close()
+}
@@ -0,0 +1,172 @@
+package grep
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Имя файла с правилами игнорирования и директории репозитория git
+const (
+	gitIgnoreName = ".gitignore"
+	gitDirName    = ".git"
+)
+
+// Правило из файла .gitignore
+type ignoreRule struct {
+	// Абсолютный путь директории, в которой находится .gitignore
+	base string
+	// Шаблон имени (для правил без "/") или сегменты пути относительно base
+	pattern  string
+	segments []string
+	anchored bool
+	dirOnly  bool
+	negate   bool
+}
+
+// Чтение правил из файла .gitignore директории dir (отсутствие файла - не ошибка)
+func readIgnoreFile(dir string) ([]ignoreRule, error) {
+	file, err := os.Open(filepath.Join(dir, gitIgnoreName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	base, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]ignoreRule, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text(), base); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// Разбор строки .gitignore (пустые строки и комментарии пропускаются)
+func parseIgnoreRule(line, base string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	// Шаблон с "/" в начале или середине задаётся относительно директории .gitignore,
+	// иначе - сопоставляется с именем файла на любой глубине
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+	} else {
+		rule.pattern = line
+	}
+	return rule, true
+}
+
+// Проверка, подходит ли файл с абсолютным путём absPath под правило
+func (rule ignoreRule) matches(absPath string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	prefix := rule.base
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if !strings.HasPrefix(absPath, prefix) {
+		return false
+	}
+	relative := filepath.ToSlash(strings.TrimPrefix(absPath, prefix))
+	if !rule.anchored {
+		ok, _ := path.Match(rule.pattern, path.Base(relative))
+		return ok
+	}
+	return matchSegments(rule.segments, strings.Split(relative, "/"))
+}
+
+// Сопоставление сегментов пути с сегментами шаблона ("**" - любое число сегментов)
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// Проверка, игнорируется ли файл (действует последнее подходящее правило)
+func isIgnored(rules []ignoreRule, absPath string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.matches(absPath, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// Правила из .gitignore родительских директорий root вплоть до корня репозитория git
+// (если root не находится в репозитории - правил нет)
+func ancestorIgnoreRules(root string) ([]ignoreRule, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0)
+	for dir := absRoot; ; {
+		if _, err := os.Stat(filepath.Join(dir, gitDirName)); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+		dirs = append(dirs, dir)
+	}
+
+	// Правила более внешних директорий идут первыми
+	rules := make([]ignoreRule, 0)
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dirRules, err := readIgnoreFile(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, dirRules...)
+	}
+	return rules, nil
+}
@@ -2,132 +2,296 @@ package grep
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
-	"regexp"
-	"slices"
+	"os"
 	"strconv"
 	"strings"
 )
 
-// Нахождение индексов строк, подходящих под фильтр (с использованием регулярных выражений)
-func FindIndexes(text []string, pattern string, invert bool) (map[int]struct{}, error) {
-	result := make(map[int]struct{}, 0)
-	compiled, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, err
-	}
-	for i, str := range text {
-		match := compiled.MatchString(str)
-		if (!invert && match) || (invert && !match) {
-			result[i] = struct{}{}
-		}
+var ErrFilesFailed error = errors.New("some files could not be searched")
+
+// Коды завершения утилиты
+const (
+	ExitFound    = 0
+	ExitNotFound = 1
+	ExitError    = 2
+)
+
+// Размер буфера чтения (и начала файла, в котором ищется нулевой байт для определения двоичного файла)
+const binaryPeekSize = 32 * 1024
+
+// Чтение очередной строки без символов конца строки и её длины в байтах (io.EOF - строк больше нет)
+func readLine(reader *bufio.Reader) (string, int, error) {
+	str, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || str == "") {
+		return "", 0, err
 	}
-	return result, nil
+	return strings.TrimSuffix(strings.TrimSuffix(str, "\n"), "\r"), len(str), nil
 }
 
-// Реализация утилиты фильтрации
+// Реализация утилиты фильтрации для одного io.Reader (без вывода имени файла)
 func GREP(in io.Reader, out io.Writer, options Options) error {
-	inReader := bufio.NewReader(in)
-	text := make([]string, 0)
-	// Получение текста из io.Reader
-	for {
-		str, err := inReader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				str = strings.TrimSuffix(strings.TrimSuffix(str, "\n"), "\r")
-				if str != "" {
-					text = append(text, str)
-				}
-				break
-			}
-			return err
-		}
-		text = append(text, strings.TrimSuffix(strings.TrimSuffix(str, "\n"), "\r"))
-	}
-	// Поиск индексов строк, подходящих под фильтр
-	result, err := FindIndexes(text, options.Pattern, options.Invert)
+	matcher, err := NewMatcher(options)
 	if err != nil {
 		return err
 	}
-	// Вывод результата в io.Writer
-	if err := GetResult(out, text, result, options.After, options.Before, options.Context, options.Count, options.LineNum); err != nil {
+	if _, err := groupIndex(matcher, options.OnlyGroup); err != nil {
 		return err
 	}
-	return nil
+	outWriter := bufio.NewWriter(out)
+	defer outWriter.Flush()
+	p := newPrinter(outWriter, options, colorEnabled(out, options.Color))
+	_, err = search(in, p, StdinName, matcher, options)
+	if err == nil && options.JSON && !options.Quiet {
+		err = writeEvent(outWriter, "summary", jsonSummary{Stats: p.stats})
+	}
+	return err
 }
 
-func GetResult(out io.Writer, text []string, indices map[int]struct{}, after, before, context int, count, lineNum bool) error {
+// Реализация утилиты фильтрации для файлов из options.Filepaths (или стандартного ввода stdin).
+// Возвращает, найдено ли совпадение; ошибки чтения отдельных
+// файлов выводятся в errOut и не прерывают поиск, в этом случае возвращается ErrFilesFailed
+func Search(stdin io.Reader, out, errOut io.Writer, options Options) (bool, error) {
+	matcher, err := NewMatcher(options)
+	if err != nil {
+		return false, err
+	}
+	if _, err := groupIndex(matcher, options.OnlyGroup); err != nil {
+		return false, err
+	}
 	outWriter := bufio.NewWriter(out)
 	defer outWriter.Flush()
 
-	var err error
-	// Если необходимо только количество строк - выводим длину map найденных индексов
-	if count {
-		if _, err = outWriter.WriteString(strconv.Itoa(len(indices))); err != nil {
-			return err
+	failed := false
+	reportError := func(err error) {
+		failed = true
+		outWriter.Flush()
+		fmt.Fprintf(errOut, "grep: %s\n", errorMessage(err))
+	}
+	// Итоговая статистика поиска по всем файлам для --json
+	var stats searchStats
+	result := func(found bool) (bool, error) {
+		if options.JSON && !options.Quiet {
+			if err := writeEvent(outWriter, "summary", jsonSummary{Stats: stats}); err != nil {
+				return found, err
+			}
 		}
-		err = outWriter.WriteByte('\n')
-		return err
+		if failed {
+			return found, ErrFilesFailed
+		}
+		return found, nil
 	}
 
-	// Унифицирование after, before и context
-	after = max(after, context)
-	before = max(before, context)
+	// При нескольких потоках файлы ищутся параллельно, результат выводится в порядке обхода
+	// (с -q поиск последовательный, чтобы остановиться на первом совпадении)
+	if options.Jobs > 1 && !options.Quiet {
+		return result(searchParallel(stdin, outWriter, reportError, &stats, matcher, colorEnabled(out, options.Color), options))
+	}
 
-	n := len(text)
-	// Функция проверки валидности индекса (индекс, не выходящий за пределы text)
-	validIndex := func(index int) bool { return 0 <= index && index < n }
+	showFilename := withFilename(options)
+	color := colorEnabled(out, options.Color)
+	found := false
+	started := new(bool)
+	walkFiles(options, func(path string) bool {
+		p := newPrinter(outWriter, options, color)
+		p.started = started
+		fileFound, err := searchPath(stdin, path, p, showFilename, matcher, options)
+		if err != nil {
+			reportError(err)
+		}
+		stats.add(p.stats)
+		found = found || fileFound
+		return !(found && options.Quiet)
+	}, reportError)
+	return result(found)
+}
 
-	// Множество индексов для вывода
-	printSet := make(map[int]struct{})
-	for index := range indices {
-		if !validIndex(index) {
-			continue
+// Код завершения утилиты: 0 - найдено совпадение, 1 - совпадений нет, 2 - ошибка
+// (с -q найденное совпадение важнее ошибок чтения файлов)
+func ExitStatus(found bool, err error, quiet bool) int {
+	switch {
+	case err != nil && !(quiet && found && errors.Is(err, ErrFilesFailed)):
+		return ExitError
+	case found:
+		return ExitFound
+	}
+	return ExitNotFound
+}
+
+// Поиск в файле path ("-" - стандартный ввод stdin)
+func searchPath(stdin io.Reader, path string, p *printer, showFilename bool, matcher Matcher, options Options) (bool, error) {
+	name := path
+	in := stdin
+	if path == "-" {
+		name = StdinName
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return false, err
 		}
-		// Добавление индексов в множество с учетом контекста
-		for i := index - before; i < index; i++ {
-			if !validIndex(i) {
-				continue
-			}
-			printSet[i] = struct{}{}
+		defer file.Close()
+		in = file
+		// С --follow чтение продолжается после конца файла
+		if options.Follow {
+			follow := newFollowReader(file, path, followInterval, nil)
+			defer follow.Close()
+			in = follow
 		}
-		printSet[index] = struct{}{}
-		for i := index + 1; i <= index+after; i++ {
-			if !validIndex(i) {
-				continue
-			}
-			printSet[i] = struct{}{}
+	}
+	if showFilename {
+		p.filename = name
+	}
+	found, err := search(in, p, name, matcher, options)
+	if err != nil {
+		return found, fmt.Errorf("%s: %w", name, err)
+	}
+	return found, nil
+}
+
+// Построчный поиск в тексте: в памяти хранятся только строки контекста перед совпадением
+// (кольцевой буфер на Before строк). Возвращает, найдена ли строка
+func search(in io.Reader, p *printer, name string, matcher Matcher, options Options) (bool, error) {
+	// С -z сжатые данные распаковываются (формат определяется по сигнатуре), имя файла в выводе не меняется
+	if options.Decompress {
+		decompressed, err := decompress(in)
+		if err != nil {
+			return false, err
 		}
+		defer decompressed.Close()
+		in = decompressed
 	}
+	reader := bufio.NewReaderSize(in, binaryPeekSize)
+	outWriter := p.writer
+	p.path = name
+	p.stats.Searches = 1
+	// Файл считается двоичным, если в первом прочитанном блоке есть нулевой байт: вместо строк выводится
+	// сообщение о совпадении (ожидание полного блока заблокировало бы потоковое чтение); в событиях JSON
+	// строки, не являющиеся текстом UTF-8, выводятся в base64
+	reader.Peek(1)
+	head, _ := reader.Peek(reader.Buffered())
+	binary := bytes.IndexByte(head, 0) >= 0 && !p.json
 
-	// Сортировка множества индексов для вывода
-	printSorted := make([]int, 0, len(printSet))
-	for index := range printSet {
-		printSorted = append(printSorted, index)
+	// Унифицирование after, before и context (с -o строки контекста не выводятся, но определяют разделение групп)
+	after := max(options.After, options.Context)
+	before := max(options.Before, options.Context)
+	// С -q, -l и -L строки не выводятся, чтение прекращается на первой найденной строке
+	listOnly := options.Quiet || options.FilesWithMatches || options.FilesWithoutMatch
+	// Позиции совпадений нужны только для -o, подсветки и --json (для --json и --only-group - вместе
+	// с группами захвата)
+	needSpans := (options.OnlyMatching || p.color || p.json) && !options.Invert && !options.Count && !listOnly
+	group, err := groupIndex(matcher, options.OnlyGroup)
+	if err != nil {
+		return false, err
+	}
+	p.group = group
+	needGroups := needSpans && (p.json || p.group > 0)
+	if needGroups {
+		p.groupNames = subexpNames(matcher)
 	}
-	slices.Sort(printSorted)
 
-	for _, index := range printSorted {
-		// Если необходимо добавить номер строки - вывод с номером строки (найденные строки имеют вид N:content, контекст N-content)
-		if lineNum {
-			outWriter.WriteString(strconv.Itoa(index + 1))
-			if _, ok := indices[index]; ok {
-				err = outWriter.WriteByte(':')
-			} else {
-				err = outWriter.WriteByte('-')
-			}
-			if err != nil {
-				return err
+	// Строки перед совпадением и количество строк, которые осталось вывести после совпадения
+	beforeLines := newRingBuffer(before)
+	remaining := 0
+	count := 0
+	var offset int64
+	var budgetErr error
+	// С -m чтение прекращается после MaxCount найденных строк и вывода строк контекста после последней из них
+	for number := 1; options.MaxCount < 0 || count < options.MaxCount || remaining > 0; number++ {
+		// Если во входном буфере нет данных - вывод накопленного результата до блокирующего чтения
+		// (чтобы найденные строки появлялись сразу, например при tail -f | grep)
+		if reader.Buffered() == 0 {
+			if err := outWriter.Flush(); err != nil {
+				return count > 0, err
 			}
 		}
+		text, size, err := readLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count > 0, err
+		}
 
-		if _, err = outWriter.WriteString(text[index]); err != nil {
-			return err
+		line := Line{Number: number, Offset: offset, Text: text}
+		offset += int64(size)
+		match, spans, err := matchField(matcher, text, options, needSpans, needGroups)
+		// Строка, поиск в которой превысил ограничение, считается не совпавшей; ошибка возвращается в конце
+		if err != nil && budgetErr == nil {
+			budgetErr = fmt.Errorf("line %d: %w", number, err)
+		}
+		line.Matches = spans
+		match = match != options.Invert && err == nil
+		// Строки после MaxCount найденных выводятся только как контекст
+		if match && options.MaxCount >= 0 && count >= options.MaxCount {
+			match = false
+		}
+		if match {
+			count++
+			p.stats.Matches += len(spans)
 		}
 
-		if err = outWriter.WriteByte('\n'); err != nil {
-			return err
+		switch {
+		case listOnly && match:
+			found, err := finishSearch(p, name, count, options)
+			return found, errors.Join(err, budgetErr)
+		// Если необходимо только количество строк - подсчёт найденных строк
+		case listOnly || options.Count:
+		case match && binary:
+			_, err := fmt.Fprintf(outWriter, "Binary file %s matches\n", name)
+			return true, errors.Join(err, budgetErr)
+		case match:
+			if err := beforeLines.Drain(func(line Line) error { return p.printLine(line, '-') }); err != nil {
+				return true, err
+			}
+			if err := p.printLine(line, ':'); err != nil {
+				return true, err
+			}
+			remaining = after
+		case remaining > 0:
+			if err := p.printLine(line, '-'); err != nil {
+				return count > 0, err
+			}
+			remaining--
+		default:
+			beforeLines.Push(line)
+		}
+	}
+	p.stats.BytesSearched = offset
+	p.stats.MatchedLines = count
+	if count > 0 {
+		p.stats.SearchesWithMatch = 1
+	}
+	if err := p.printJSONEnd(); err != nil {
+		return count > 0, err
+	}
+	found, err := finishSearch(p, name, count, options)
+	return found, errors.Join(err, budgetErr)
+}
+
+// Вывод итога поиска в файле для -c, -l и -L (для кода завершения, как и в GNU grep, важно только,
+// найдены ли строки, а не выведено ли имя файла)
+func finishSearch(p *printer, name string, count int, options Options) (bool, error) {
+	var err error
+	switch {
+	case options.Quiet:
+	case options.FilesWithMatches:
+		if count > 0 {
+			_, err = p.writer.WriteString(name + "\n")
+		}
+	case options.FilesWithoutMatch:
+		if count == 0 {
+			_, err = p.writer.WriteString(name + "\n")
+		}
+	case options.Count:
+		if p.filename != "" {
+			if _, err := p.writer.WriteString(p.filename + ":"); err != nil {
+				return count > 0, err
+			}
 		}
+		_, err = p.writer.WriteString(strconv.Itoa(count) + "\n")
 	}
-	return nil
+	return count > 0, err
 }
@@ -1,9 +1,26 @@
 package grep
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestGREP(t *testing.T) {
@@ -68,6 +85,18 @@ func TestGREP(t *testing.T) {
 			expectedOutput: "Grep.\n",
 			options:        NewOptions([]string{}, "Grep\\.", 0, 0, 0, false, false, false),
 			expectedError:  nil,
+		}, {
+			name:           "Overlapping context",
+			inputText:      "a\nmatch\nb\nc\nmatch\nd\ne\nf\ng\nmatch\n",
+			expectedOutput: "1-a\n2:match\n3-b\n4-c\n5:match\n6-d\n--\n8-f\n9-g\n10:match\n",
+			options:        NewOptions([]string{}, "match", 1, 2, 0, false, false, true),
+			expectedError:  nil,
+		}, {
+			name:           "Last line without newline",
+			inputText:      "first\r\nsecond match",
+			expectedOutput: "first\nsecond match\n",
+			options:        NewOptions([]string{}, "match", 0, 0, 1, false, false, false),
+			expectedError:  nil,
 		}, {
 			name:           "Pattern test",
 			inputText:      "Hello World\nTest\nGrep\nHELLO again\nGrep again\nAnother line\nGREP UPPER\nSample text here\nhello one more time\nEnd of file\n",
@@ -97,6 +126,1151 @@ func TestGREP(t *testing.T) {
 	}
 }
 
+func TestGREPMatchOutput(t *testing.T) {
+	const inputText = "foo bar foo\r\nnothing\nbarfoo\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+	}{
+		{
+			name:           "Only matching",
+			arguments:      []string{"-o", "foo"},
+			expectedOutput: "foo\nfoo\nfoo\n",
+		}, {
+			name:           "Only matching with offsets",
+			arguments:      []string{"-o", "-b", "-n", "fo*"},
+			expectedOutput: "1:0:foo\n1:8:foo\n3:24:foo\n",
+		}, {
+			name:           "Only matching skips empty matches",
+			arguments:      []string{"-o", "x*"},
+			expectedOutput: "",
+		}, {
+			name:           "Only matching ignores context",
+			arguments:      []string{"-o", "-C", "1", "bar$"},
+			expectedOutput: "",
+		}, {
+			name:           "Byte offset of lines",
+			arguments:      []string{"-b", "-A", "1", "foo bar"},
+			expectedOutput: "0:foo bar foo\n13-nothing\n",
+		}, {
+			name:           "Color",
+			arguments:      []string{"--color=always", "-n", "foo"},
+			expectedOutput: "\x1b[32m\x1b[K1\x1b[m\x1b[K\x1b[36m\x1b[K:\x1b[m\x1b[K\x1b[01;31m\x1b[Kfoo\x1b[m\x1b[K bar \x1b[01;31m\x1b[Kfoo\x1b[m\x1b[K\n\x1b[32m\x1b[K3\x1b[m\x1b[K\x1b[36m\x1b[K:\x1b[m\x1b[Kbar\x1b[01;31m\x1b[Kfoo\x1b[m\x1b[K\n",
+		}, {
+			name:           "Color never",
+			arguments:      []string{"--color", "never", "foo"},
+			expectedOutput: "foo bar foo\nbarfoo\n",
+		}, {
+			name:           "Color auto without terminal",
+			arguments:      []string{"--color=auto", "-v", "foo"},
+			expectedOutput: "nothing\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestAhoCorasick(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	alphabet := []rune("abcАбв")
+	randomString := func(maxLength int) string {
+		runes := make([]rune, random.Intn(maxLength+1))
+		for i := range runes {
+			runes[i] = alphabet[random.Intn(len(alphabet))]
+		}
+		return string(runes)
+	}
+
+	// Результат совпадает с регулярным выражением из альтернатив в режиме "самое левое, самое длинное"
+	for i := 0; i < 500; i++ {
+		patterns := make([]string, random.Intn(5)+1)
+		for j := range patterns {
+			patterns[j] = randomString(4)
+			if patterns[j] == "" {
+				patterns[j] = "a"
+			}
+		}
+		ignoreCase := i%2 == 0
+		automaton := NewAhoCorasick(patterns, ignoreCase)
+		expression := regexp.MustCompile(joinPatterns(patterns, Options{Fixed: true, IgnoreCase: ignoreCase}))
+		expression.Longest()
+		for j := 0; j < 10; j++ {
+			text := randomString(30)
+			if got, want := automaton.MatchString(text), expression.MatchString(text); got != want {
+				t.Fatalf("match %q in %q: got %v, want %v", patterns, text, got, want)
+			}
+			if got, want := automaton.FindAllStringIndex(text, -1), expression.FindAllStringIndex(text, -1); !reflect.DeepEqual(got, want) {
+				t.Fatalf("find %q in %q: got %v, want %v", patterns, text, got, want)
+			}
+		}
+	}
+
+	// Перекрывающиеся строки: совпадения не пересекаются, каждое - самое длинное из начинающихся левее всех
+	automaton := NewAhoCorasick([]string{"a", "aa", "aaa"}, false)
+	text := strings.Repeat("a", 3001)
+	expected := make([][]int, 0, 1001)
+	for start := 0; start < len(text); start += 3 {
+		expected = append(expected, []int{start, min(start+3, len(text))})
+	}
+	if got := automaton.FindAllStringIndex(text, -1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("overlapping patterns: got %d spans, want %d", len(got), len(expected))
+	}
+	if got, want := automaton.FindAllStringIndex("aaaaaaa", 2), [][]int{{0, 3}, {3, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("limited result: got %v, want %v", got, want)
+	}
+
+	// Пустая строка подходит под любую строку текста
+	automaton = NewAhoCorasick([]string{"", "abc"}, false)
+	if !automaton.MatchString("xyz") {
+		t.Errorf("match empty pattern: got %v, want %v", false, true)
+	}
+	if got, want := automaton.FindAllStringIndex("xabc", -1), [][]int{{1, 4}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("result: got %v, want %v", got, want)
+	}
+}
+
+func TestGREPMultiplePatterns(t *testing.T) {
+	patternFile := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := os.WriteFile(patternFile, []byte("id-2\r\nID-4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(emptyFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	const inputText = "user id-1\nuser id-2\nuser id-3\nuser id-4\nuser id.5\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+	}{
+		{
+			name:           "Repeated -e",
+			arguments:      []string{"-e", "id-1", "-e", "id.5"},
+			expectedOutput: "user id-1\nuser id.5\n",
+		}, {
+			name:           "Regexp -e",
+			arguments:      []string{"-e", "id.[15]"},
+			expectedOutput: "user id-1\nuser id.5\n",
+		}, {
+			name:           "Fixed -e",
+			arguments:      []string{"-F", "-e", "id.5", "-e", "id-3"},
+			expectedOutput: "user id-3\nuser id.5\n",
+		}, {
+			name:           "Pattern file",
+			arguments:      []string{"-F", "-i", "-f", patternFile},
+			expectedOutput: "user id-2\nuser id-4\n",
+		}, {
+			name:           "Pattern file and -e",
+			arguments:      []string{"-n", "-f", patternFile, "-e", "id-1"},
+			expectedOutput: "1:user id-1\n2:user id-2\n",
+		}, {
+			name:           "Fixed only matching",
+			arguments:      []string{"-o", "-F", "-e", "id", "-e", "id-3", "-e", "user id"},
+			expectedOutput: "user id\nuser id\nuser id\nuser id\nuser id\n",
+		}, {
+			name:           "Empty pattern file",
+			arguments:      []string{"-f", emptyFile},
+			expectedOutput: "",
+		}, {
+			name:           "Empty pattern file inverted",
+			arguments:      []string{"-v", "-c", "-F", "-f", emptyFile},
+			expectedOutput: "5\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestGREPLineSelection(t *testing.T) {
+	const inputText = "кот\nкотлета\nскот и кот\nfoo_bar foo\nfoo-bar\nfoobar\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+	}{
+		{
+			name:           "Unicode words",
+			arguments:      []string{"-w", "-n", "кот"},
+			expectedOutput: "1:кот\n3:скот и кот\n",
+		}, {
+			name:           "Word after failed candidate",
+			arguments:      []string{"-w", "-o", "-b", "foo"},
+			expectedOutput: "49:foo\n53:foo\n",
+		}, {
+			name:           "Fixed words",
+			arguments:      []string{"-w", "-F", "-e", "bar", "-e", "кот"},
+			expectedOutput: "кот\nскот и кот\nfoo-bar\n",
+		}, {
+			name:           "Whole lines",
+			arguments:      []string{"-x", "кот|foobar"},
+			expectedOutput: "кот\nfoobar\n",
+		}, {
+			name:           "Whole lines leftmost alternative",
+			arguments:      []string{"-x", "-e", "foo", "-e", "foobar"},
+			expectedOutput: "foobar\n",
+		}, {
+			name:           "Fixed whole lines",
+			arguments:      []string{"-x", "-F", "-i", "-e", "FOO", "-e", "FOOBAR", "-e", "Кот"},
+			expectedOutput: "кот\nfoobar\n",
+		}, {
+			name:           "Max count",
+			arguments:      []string{"-m", "2", "кот"},
+			expectedOutput: "кот\nкотлета\n",
+		}, {
+			name:           "Max count with context",
+			arguments:      []string{"-m", "1", "-A", "2", "-n", "кот"},
+			expectedOutput: "1:кот\n2-котлета\n3-скот и кот\n",
+		}, {
+			name:           "Max count with count",
+			arguments:      []string{"-m", "2", "-c", "o"},
+			expectedOutput: "2\n",
+		}, {
+			name:           "Zero max count",
+			arguments:      []string{"-m", "0", "кот"},
+			expectedOutput: "",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestSearchExitStatus(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "match\n", "b.txt": "other\n", "c.txt": "match\nmatch\n"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b, c := filepath.Join(root, "a.txt"), filepath.Join(root, "b.txt"), filepath.Join(root, "c.txt")
+	missing := filepath.Join(root, "missing.txt")
+
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+		expectedStatus int
+	}{
+		{
+			name:           "Files with matches",
+			arguments:      []string{"-l", "match", a, b, c},
+			expectedOutput: a + "\n" + c + "\n",
+			expectedStatus: ExitFound,
+		}, {
+			name:           "Files without match",
+			arguments:      []string{"-L", "match", a, b, c},
+			expectedOutput: b + "\n",
+			expectedStatus: ExitFound,
+		}, {
+			// Код завершения -L, как и в GNU grep 3.5+, зависит от того, найдены ли строки
+			name:           "All files match",
+			arguments:      []string{"-L", "match", a, c},
+			expectedOutput: "",
+			expectedStatus: ExitFound,
+		}, {
+			name:           "No file matches",
+			arguments:      []string{"-L", "nothing", a, b},
+			expectedOutput: a + "\n" + b + "\n",
+			expectedStatus: ExitNotFound,
+		}, {
+			name:           "No match",
+			arguments:      []string{"nothing", a, b},
+			expectedOutput: "",
+			expectedStatus: ExitNotFound,
+		}, {
+			name:           "Quiet",
+			arguments:      []string{"-q", "-j", "4", "match", a, c},
+			expectedOutput: "",
+			expectedStatus: ExitFound,
+		}, {
+			name:           "Quiet with error after match",
+			arguments:      []string{"-q", "match", a, missing},
+			expectedOutput: "",
+			expectedStatus: ExitFound,
+		}, {
+			name:           "Error",
+			arguments:      []string{"match", missing, b},
+			expectedOutput: "",
+			expectedStatus: ExitError,
+		}, {
+			name:           "Match with error",
+			arguments:      []string{"-h", "match", a, missing},
+			expectedOutput: "match\n",
+			expectedStatus: ExitError,
+		}, {
+			name:           "Invalid pattern",
+			arguments:      []string{"(", a},
+			expectedOutput: "",
+			expectedStatus: ExitError,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, errOut bytes.Buffer
+			found, err := Search(nil, &out, &errOut, options)
+			if got := ExitStatus(found, err, options.Quiet); got != testCase.expectedStatus {
+				t.Errorf("status: got %v, want %v (error %v)", got, testCase.expectedStatus, err)
+			}
+			if got := out.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestGREPPerl(t *testing.T) {
+	const inputText = "abcabc\nabcabd\nцена: 100 руб\nцена: 200 $\nкот\nскот\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+	}{
+		{
+			name:           "Backreference",
+			arguments:      []string{"-P", `^(\w+)\1$`},
+			expectedOutput: "abcabc\n",
+		}, {
+			name:           "Lookahead",
+			arguments:      []string{"-P", "-o", `\d+(?= руб)`},
+			expectedOutput: "100\n",
+		}, {
+			name:           "Lookbehind with byte offsets",
+			arguments:      []string{"-P", "-o", "-b", `(?<=цена: )\d+`},
+			expectedOutput: "24:100\n45:200\n",
+		}, {
+			name:           "Words",
+			arguments:      []string{"-P", "-w", "-i", "КОТ"},
+			expectedOutput: "кот\n",
+		}, {
+			name:           "Whole lines",
+			arguments:      []string{"-P", "-x", "-e", "abc", "-e", `abc(?!abd)\w+`},
+			expectedOutput: "abcabc\n",
+		}, {
+			name:           "Empty pattern file",
+			arguments:      []string{"-P", "-c", "-f", os.DevNull},
+			expectedOutput: "0\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+
+	// Катастрофический шаблон прерывается по ограничению времени, остальные строки обрабатываются
+	options, err := ParseArguments([]string{"-P", "-v", "--perl-timeout", "50ms", `^(a+)+$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	start := time.Now()
+	err = GREP(strings.NewReader("b\n"+strings.Repeat("a", 40)+"b\nc\n"), &buffer, options)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("error: got %v, want %v", err, ErrBudgetExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("time: got %v, want less than %v", elapsed, 5*time.Second)
+	}
+	if got, want := buffer.String(), "b\nc\n"; got != want {
+		t.Errorf("result: got %q, want %q", got, want)
+	}
+}
+
+func TestGREPFields(t *testing.T) {
+	const inputText = "GET\t/index\t200\tid=17\nPOST\t/login\t500\tid=3\nshort\t500\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+		expectedError  error
+	}{
+		{
+			name:           "Field",
+			arguments:      []string{"--field", "3", "500"},
+			expectedOutput: "POST\t/login\t500\tid=3\n",
+		}, {
+			name:           "Anchored field",
+			arguments:      []string{"--field", "1", "-x", "GET|short"},
+			expectedOutput: "GET\t/index\t200\tid=17\nshort\t500\n",
+		}, {
+			name:           "Missing field",
+			arguments:      []string{"--field", "4", "-v", "id"},
+			expectedOutput: "short\t500\n",
+		}, {
+			name:           "Custom delimiter",
+			arguments:      []string{"--field", "2", "--delimiter", "=", "-o", "-b", "1."},
+			expectedOutput: "18:17\n",
+		}, {
+			name:           "Only named group",
+			arguments:      []string{"--only-group", "id", "-n", `id=(?P<id>\d+)`},
+			expectedOutput: "1:17\n2:3\n",
+		}, {
+			name:           "Only numbered group in field",
+			arguments:      []string{"--field", "2", "--only-group", "1", "-b", `/(\w)`},
+			expectedOutput: "5:i\n27:l\n",
+		}, {
+			name:           "Word group keeps pattern submatches",
+			arguments:      []string{"-w", "--only-group", "1", "(id)|id=(17)"},
+			expectedOutput: "id\nid\n",
+		}, {
+			name:           "Perl named group",
+			arguments:      []string{"-P", "--only-group=code", `\t(?<code>5\d\d)(?=\t)`},
+			expectedOutput: "500\n",
+		}, {
+			name:           "Unknown group",
+			arguments:      []string{"--only-group", "name", `id=(\d+)`},
+			expectedOutput: "",
+			expectedError:  ErrUnknownGroup,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); !errors.Is(err, testCase.expectedError) {
+				t.Errorf("error: got %v, want %v", err, testCase.expectedError)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestGREPJSON(t *testing.T) {
+	const inputText = "key=1 other=22\nnothing\n\xffkey=3\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedEvents []string
+	}{
+		{
+			name:      "Match with context and groups",
+			arguments: []string{"--json", "-A", "1", "-m", "1", `(?P<name>\w+)=(\d)|(x)`},
+			expectedEvents: []string{
+				`{"type":"begin","data":{"path":{"text":"(standard input)"}}}`,
+				`{"type":"match","data":{"path":{"text":"(standard input)"},"lines":{"text":"key=1 other=22"},"line_number":1,"absolute_offset":0,"submatches":[` +
+					`{"match":{"text":"key=1"},"start":0,"end":5,"groups":[{"number":1,"name":"name","match":{"text":"key"},"start":0,"end":3},{"number":2,"match":{"text":"1"},"start":4,"end":5},{"number":3,"match":null,"start":-1,"end":-1}]},` +
+					`{"match":{"text":"other=2"},"start":6,"end":13,"groups":[{"number":1,"name":"name","match":{"text":"other"},"start":6,"end":11},{"number":2,"match":{"text":"2"},"start":12,"end":13},{"number":3,"match":null,"start":-1,"end":-1}]}]}}`,
+				`{"type":"context","data":{"path":{"text":"(standard input)"},"lines":{"text":"nothing"},"line_number":2,"absolute_offset":15,"submatches":[]}}`,
+				`{"type":"end","data":{"path":{"text":"(standard input)"},"stats":{"searches":1,"searches_with_match":1,"bytes_searched":23,"matched_lines":1,"matches":2}}}`,
+				`{"type":"summary","data":{"stats":{"searches":1,"searches_with_match":1,"bytes_searched":23,"matched_lines":1,"matches":2}}}`,
+			},
+		}, {
+			name:      "Perl groups and invalid UTF-8",
+			arguments: []string{"--json", "-P", "-w", "-n", `key=(?<value>\d)`},
+			expectedEvents: []string{
+				`{"type":"begin","data":{"path":{"text":"(standard input)"}}}`,
+				`{"type":"match","data":{"path":{"text":"(standard input)"},"lines":{"text":"key=1 other=22"},"line_number":1,"absolute_offset":0,"submatches":[{"match":{"text":"key=1"},"start":0,"end":5,"groups":[{"number":1,"name":"value","match":{"text":"1"},"start":4,"end":5}]}]}}`,
+				`{"type":"match","data":{"path":{"text":"(standard input)"},"lines":{"bytes":"/2tleT0z"},"line_number":3,"absolute_offset":23,"submatches":[{"match":{"text":"key=3"},"start":1,"end":6,"groups":[{"number":1,"name":"value","match":{"text":"3"},"start":5,"end":6}]}]}}`,
+				`{"type":"end","data":{"path":{"text":"(standard input)"},"stats":{"searches":1,"searches_with_match":1,"bytes_searched":30,"matched_lines":2,"matches":2}}}`,
+				`{"type":"summary","data":{"stats":{"searches":1,"searches_with_match":1,"bytes_searched":30,"matched_lines":2,"matches":2}}}`,
+			},
+		}, {
+			name:      "Invert",
+			arguments: []string{"--json", "-v", "-F", "="},
+			expectedEvents: []string{
+				`{"type":"begin","data":{"path":{"text":"(standard input)"}}}`,
+				`{"type":"match","data":{"path":{"text":"(standard input)"},"lines":{"text":"nothing"},"line_number":2,"absolute_offset":15,"submatches":[]}}`,
+				`{"type":"end","data":{"path":{"text":"(standard input)"},"stats":{"searches":1,"searches_with_match":1,"bytes_searched":30,"matched_lines":1,"matches":0}}}`,
+				`{"type":"summary","data":{"stats":{"searches":1,"searches_with_match":1,"bytes_searched":30,"matched_lines":1,"matches":0}}}`,
+			},
+		}, {
+			name:      "No matches",
+			arguments: []string{"--json", "-C", "1", "missing"},
+			expectedEvents: []string{
+				`{"type":"summary","data":{"stats":{"searches":1,"searches_with_match":0,"bytes_searched":30,"matched_lines":0,"matches":0}}}`,
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := GREP(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %v, want %v", err, nil)
+			}
+			got := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+			if !reflect.DeepEqual(got, testCase.expectedEvents) {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedEvents)
+			}
+		})
+	}
+}
+
+func TestGREPStreaming(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- GREP(inReader, outWriter, NewOptions([]string{}, "match", 0, 0, 0, false, false, false))
+		outWriter.Close()
+	}()
+
+	// Найденная строка выводится до окончания входных данных
+	lines := bufio.NewReader(outReader)
+	for _, input := range []string{"skip\nfirst match\n", "skip\nsecond match\n"} {
+		if _, err := io.WriteString(inWriter, input); err != nil {
+			t.Fatal(err)
+		}
+		result := make(chan string, 1)
+		go func() {
+			line, _ := lines.ReadString('\n')
+			result <- line
+		}()
+		select {
+		case got := <-result:
+			if want := strings.Split(input, "\n")[1] + "\n"; got != want {
+				t.Errorf("result: got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("match was not flushed before end of input")
+		}
+	}
+	inWriter.Close()
+	if err := <-done; err != nil {
+		t.Errorf("error: got %v, want %v", err, nil)
+	}
+}
+
+func TestGREPFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("match 1\nother\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	follow := newFollowReader(file, path, 10*time.Millisecond, stop)
+	defer follow.Close()
+
+	outReader, outWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- GREP(follow, outWriter, NewOptions([]string{}, "match", 1, 0, 0, false, false, true))
+		outWriter.Close()
+	}()
+
+	appendFile := func(name, text string) {
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if _, err := file.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCases := []struct {
+		name          string
+		change        func()
+		expectedLines []string
+	}{
+		{
+			name:          "Existing lines",
+			change:        func() {},
+			expectedLines: []string{"1:match 1", "2-other"},
+		}, {
+			// Строка контекста после совпадения дописывается отдельно
+			name:          "Appended lines",
+			change:        func() { appendFile(path, "match 2\n") },
+			expectedLines: []string{"3:match 2"},
+		}, {
+			name:          "Context across reads",
+			change:        func() { appendFile(path, "context\nskip\n") },
+			expectedLines: []string{"4-context"},
+		}, {
+			name: "Truncation",
+			change: func() {
+				if err := os.WriteFile(path, []byte("match 3\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectedLines: []string{"--", "6:match 3"},
+		}, {
+			name: "Rotation",
+			change: func() {
+				appendFile(path, "match 4\n")
+				if err := os.Rename(path, path+".1"); err != nil {
+					t.Fatal(err)
+				}
+				appendFile(path, "match 5\n")
+			},
+			expectedLines: []string{"7:match 4", "8:match 5"},
+		},
+	}
+	lines := bufio.NewReader(outReader)
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.change()
+			for _, want := range testCase.expectedLines {
+				result := make(chan string, 1)
+				go func() {
+					line, _ := lines.ReadString('\n')
+					result <- strings.TrimSuffix(line, "\n")
+				}()
+				select {
+				case got := <-result:
+					if got != want {
+						t.Errorf("result: got %q, want %q", got, want)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("line %q was not printed", want)
+				}
+			}
+		})
+	}
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("error: got %v, want %v", err, nil)
+	}
+}
+
+func TestFollowRewritten(t *testing.T) {
+	// Файл усечён и дописан дальше прочитанного между проверками: чтение начинается сначала
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("old 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	follow := newFollowReader(file, path, time.Millisecond, stop)
+	defer follow.Close()
+
+	read := func() string {
+		data, err := io.ReadAll(follow)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	if got, want := read(), "old 1\n"; got != want {
+		t.Errorf("result: got %q, want %q", got, want)
+	}
+	rewrite, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rewrite.WriteString("new 1\nnew 2\n"); err != nil {
+		t.Fatal(err)
+	}
+	rewrite.Close()
+	if got, want := read(), "new 1\nnew 2\n"; got != want {
+		t.Errorf("result: got %q, want %q", got, want)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	buffer := newRingBuffer(3)
+	for number := 1; number <= 5; number++ {
+		buffer.Push(Line{Number: number})
+	}
+	got := make([]int, 0)
+	collect := func(line Line) error {
+		got = append(got, line.Number)
+		return nil
+	}
+	buffer.Drain(collect)
+	buffer.Push(Line{Number: 6})
+	buffer.Drain(collect)
+	if want := []int{3, 4, 5, 6}; !slices.Equal(got, want) {
+		t.Errorf("result: got %v, want %v", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a.txt":            "match a\nother\n",
+		"b.log":            "match b\n",
+		"sub/c.txt":        "no\nmatch c\n",
+		"vendor/d.txt":     "match d\n",
+		"binary.bin":       "match\x00binary\n",
+		"sub/deep/e.txt":   "match e\n",
+		"sub/deep/skip.go": "match skip\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	testCases := []struct {
+		name           string
+		arguments      []string
+		stdin          string
+		expectedOutput string
+		expectedErrors string
+	}{
+		{
+			name:           "Single file",
+			arguments:      []string{"match", "a.txt"},
+			expectedOutput: "match a\n",
+		}, {
+			name:           "Multiple files",
+			arguments:      []string{"-n", "match", "a.txt", "b.log"},
+			expectedOutput: "a.txt:1:match a\nb.log:1:match b\n",
+		}, {
+			name:           "No filename",
+			arguments:      []string{"-h", "match", "a.txt", "b.log"},
+			expectedOutput: "match a\nmatch b\n",
+		}, {
+			name:           "With filename",
+			arguments:      []string{"-H", "-c", "match", "a.txt"},
+			expectedOutput: "a.txt:1\n",
+		}, {
+			name:           "Context with filename",
+			arguments:      []string{"-A", "1", "a", "a.txt", "b.log"},
+			expectedOutput: "a.txt:match a\na.txt-other\n--\nb.log:match b\n",
+		}, {
+			name:           "Parallel context with filename",
+			arguments:      []string{"-j", "2", "-A", "1", "a", "a.txt", "b.log"},
+			expectedOutput: "a.txt:match a\na.txt-other\n--\nb.log:match b\n",
+		}, {
+			name:           "Recursive",
+			arguments:      []string{"-r", "--exclude-dir=vendor", "--exclude", "*.go", "match"},
+			expectedOutput: "a.txt:match a\nb.log:match b\nBinary file binary.bin matches\nsub/c.txt:match c\nsub/deep/e.txt:match e\n",
+		}, {
+			name:           "Recursive parallel",
+			arguments:      []string{"-r", "-j", "3", "--exclude-dir=vendor", "--exclude", "*.go", "match"},
+			expectedOutput: "a.txt:match a\nb.log:match b\nBinary file binary.bin matches\nsub/c.txt:match c\nsub/deep/e.txt:match e\n",
+		}, {
+			name:           "Parallel errors in order",
+			arguments:      []string{"-j", "2", "match", "a.txt", "missing.txt", "b.log"},
+			expectedOutput: "a.txt:match a\nb.log:match b\n",
+			expectedErrors: "grep: missing.txt: No such file or directory\n",
+		}, {
+			name:      "JSON summary",
+			arguments: []string{"--json", "-j", "2", "match b", "a.txt", "b.log"},
+			expectedOutput: `{"type":"begin","data":{"path":{"text":"b.log"}}}` + "\n" +
+				`{"type":"match","data":{"path":{"text":"b.log"},"lines":{"text":"match b"},"line_number":1,"absolute_offset":0,"submatches":[{"match":{"text":"match b"},"start":0,"end":7,"groups":[]}]}}` + "\n" +
+				`{"type":"end","data":{"path":{"text":"b.log"},"stats":{"searches":1,"searches_with_match":1,"bytes_searched":8,"matched_lines":1,"matches":1}}}` + "\n" +
+				`{"type":"summary","data":{"stats":{"searches":2,"searches_with_match":1,"bytes_searched":22,"matched_lines":1,"matches":1}}}` + "\n",
+		}, {
+			name:           "Recursive include",
+			arguments:      []string{"-r", "--include=*.txt", "match", "sub"},
+			expectedOutput: "sub/c.txt:match c\nsub/deep/e.txt:match e\n",
+		}, {
+			name:           "Recursive from current directory",
+			arguments:      []string{"-r", "--exclude-dir=vendor", "--include=*.txt", "match", "."},
+			expectedOutput: "./a.txt:match a\n./sub/c.txt:match c\n./sub/deep/e.txt:match e\n",
+		}, {
+			name:           "Recursive with trailing slash",
+			arguments:      []string{"-r", "--include=*.txt", "match", "sub//"},
+			expectedOutput: "sub/c.txt:match c\nsub/deep/e.txt:match e\n",
+		}, {
+			name:           "Recursive missing directory",
+			arguments:      []string{"-r", "match", "missing"},
+			expectedErrors: "grep: missing: No such file or directory\n",
+		}, {
+			name:           "Directory without -r",
+			arguments:      []string{"match", "sub", "b.log"},
+			expectedOutput: "b.log:match b\n",
+			expectedErrors: "grep: sub: Is a directory\n",
+		}, {
+			name:           "Missing file",
+			arguments:      []string{"match", "missing.txt", "-"},
+			stdin:          "match stdin\n",
+			expectedOutput: "(standard input):match stdin\n",
+			expectedErrors: "grep: missing.txt: No such file or directory\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, errOut bytes.Buffer
+			_, err = Search(strings.NewReader(testCase.stdin), &out, &errOut, options)
+			if (err != nil) != (testCase.expectedErrors != "") {
+				t.Errorf("error: got %v, want %v", err, testCase.expectedErrors)
+			}
+			if got := out.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+			if got := errOut.String(); got != testCase.expectedErrors {
+				t.Errorf("errors: got %q, want %q", got, testCase.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestSearchParallelOrder(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 100; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("dir%d", i%7))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := strings.Repeat(fmt.Sprintf("line %d match\nother\n", i), i%5+1)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var expected string
+	for _, jobs := range []string{"1", "4", "16"} {
+		options, err := ParseArguments([]string{"-r", "-n", "-A", "1", "-j", jobs, "match", root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out, errOut bytes.Buffer
+		if _, err := Search(nil, &out, &errOut, options); err != nil {
+			t.Fatal(err)
+		}
+		if jobs == "1" {
+			expected = out.String()
+			continue
+		}
+		if got := out.String(); got != expected {
+			t.Errorf("jobs %s: output differs from sequential search", jobs)
+		}
+	}
+}
+
+func TestGitIgnore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/config":        "match git\n",
+		".gitignore":         "*.log\n!keep.log\n/build/\ndocs/**/*.tmp\n",
+		"src/main.txt":       "match main\n",
+		"src/debug.log":      "match debug\n",
+		"src/keep.log":       "match keep\n",
+		"build/out.txt":      "match build\n",
+		"src/build/out.txt":  "match nested build\n",
+		"docs/a/b/draft.tmp": "match draft\n",
+		"docs/readme.txt":    "match docs\n",
+		"src/.gitignore":     "gen*\n",
+		"src/gen/code.txt":   "match generated\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedOutput string
+	}{
+		{
+			name:           "Repository root",
+			path:           root,
+			expectedOutput: "docs/readme.txt:match docs\nsrc/build/out.txt:match nested build\nsrc/keep.log:match keep\nsrc/main.txt:match main\n",
+		}, {
+			// Правила .gitignore родительских директорий учитываются при поиске в поддиректории
+			name:           "Subdirectory",
+			path:           filepath.Join(root, "src"),
+			expectedOutput: "build/out.txt:match nested build\nkeep.log:match keep\nmain.txt:match main\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments([]string{"-r", "--gitignore", "match", testCase.path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, errOut bytes.Buffer
+			if _, err := Search(nil, &out, &errOut, options); err != nil {
+				t.Fatal(err)
+			}
+			expected := strings.ReplaceAll(testCase.expectedOutput, "\n", "\n"+testCase.path+"/")
+			expected = testCase.path + "/" + strings.TrimSuffix(expected, testCase.path+"/")
+			if got := out.String(); got != expected {
+				t.Errorf("result: got %q, want %q", got, expected)
+			}
+		})
+	}
+}
+
+func TestSearchCompressed(t *testing.T) {
+	root := t.TempDir()
+	text := "first line\nmatch %s\nlast line\n"
+	var gzipped, zstded bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	gzipWriter.Write([]byte(fmt.Sprintf(text, "gzip")))
+	gzipWriter.Close()
+	zstdWriter, err := zstd.NewWriter(&zstded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zstdWriter.Write([]byte(fmt.Sprintf(text, "zstd")))
+	zstdWriter.Close()
+	// У bzip2 в стандартной библиотеке нет упаковщика, поэтому сжатый файл лежит в testdata
+	bzipped, err := os.ReadFile(filepath.Join("testdata", "compressed", "text.bz2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"text.gz":    gzipped.Bytes(),
+		"text.zst":   zstded.Bytes(),
+		"text.bz2":   bzipped,
+		"plain.txt":  []byte(fmt.Sprintf(text, "plain")),
+		"bz.txt":     []byte("BZh match not bzip2\n"),
+		"broken.gz":  {0x1f, 0x8b, 'm', 'a', 't', 'c', 'h'},
+		"short.txt":  {0x1f},
+		"zstd.txt":   {0x28, 0xb5, '\n', 'm', 'a', 't', 'c', 'h', '\n'},
+		"gzip.empty": {},
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		arguments      []string
+		stdin          []byte
+		expectedOutput string
+		expectedErrors bool
+	}{
+		{
+			name:           "Compressed files",
+			arguments:      []string{"-z", "-n", "match", "text.gz", "text.bz2", "text.zst", "plain.txt"},
+			expectedOutput: "text.gz:2:match gzip\ntext.bz2:2:match bzip2\ntext.zst:2:match zstd\nplain.txt:2:match plain\n",
+		}, {
+			name:           "Compressed stdin",
+			arguments:      []string{"-z", "match"},
+			stdin:          gzipped.Bytes(),
+			expectedOutput: "match gzip\n",
+		}, {
+			name:           "Parallel",
+			arguments:      []string{"-z", "-j", "3", "-c", "line", "text.gz", "text.bz2", "text.zst"},
+			expectedOutput: "text.gz:2\ntext.bz2:2\ntext.zst:2\n",
+		}, {
+			// Начало сигнатуры без остальных байтов - обычный текст
+			name:           "Partial magic",
+			arguments:      []string{"-z", "-c", "match", "bz.txt", "short.txt", "zstd.txt", "gzip.empty"},
+			expectedOutput: "bz.txt:1\nshort.txt:0\nzstd.txt:1\ngzip.empty:0\n",
+		}, {
+			// Без -z сжатые данные не распаковываются
+			name:           "Without -z",
+			arguments:      []string{"-c", "match", "broken.gz", "plain.txt"},
+			expectedOutput: "broken.gz:1\nplain.txt:1\n",
+		}, {
+			name:           "Broken archive",
+			arguments:      []string{"-z", "match", "broken.gz", "plain.txt"},
+			expectedOutput: "plain.txt:match plain\n",
+			expectedErrors: true,
+		},
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, errOut bytes.Buffer
+			_, err = Search(bytes.NewReader(testCase.stdin), &out, &errOut, options)
+			if (err != nil) != testCase.expectedErrors {
+				t.Errorf("error: got %v, want error %v", err, testCase.expectedErrors)
+			}
+			if got := out.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
+
+// Перезапись эталонов GNU grep: go test ./grep -run TestGNUConformance -record (нужен GNU grep в PATH)
+var record = flag.Bool("record", false, "record GNU grep fixtures")
+
+// Эталонный результат GNU grep
+type gnuFixture struct {
+	Output string `json:"output"`
+	Status int    `json:"status"`
+}
+
+func TestGNUConformance(t *testing.T) {
+	const fixturesPath = "fixtures.json"
+	testCases := []struct {
+		name      string
+		arguments []string
+	}{
+		{name: "after with numbers", arguments: []string{"-n", "-A", "1", "foo", "text.txt"}},
+		{name: "context two files", arguments: []string{"-C", "1", "foo", "text.txt", "other.txt"}},
+		{name: "before with numbers", arguments: []string{"-B", "2", "-n", "foo", "text.txt"}},
+		{name: "count inverted", arguments: []string{"-c", "-v", "foo", "text.txt"}},
+		{name: "count with context", arguments: []string{"-c", "-C", "2", "foo", "text.txt", "other.txt"}},
+		{name: "custom separator", arguments: []string{"-n", "-C", "1", "--group-separator=***", "foo", "text.txt"}},
+		{name: "no separator", arguments: []string{"-A", "1", "--no-group-separator", "foo", "text.txt"}},
+		{name: "inverted context", arguments: []string{"-v", "-n", "-A", "1", "foo", "text.txt"}},
+		{name: "filename numbers offsets", arguments: []string{"-H", "-n", "-b", "-C", "1", "foo", "text.txt"}},
+		{name: "max count context", arguments: []string{"-m", "1", "-A", "2", "foo", "text.txt"}},
+		{name: "only matching offsets", arguments: []string{"-o", "-b", "-n", "fo*", "text.txt"}},
+		{name: "only matching context", arguments: []string{"-o", "-b", "-C", "1", "foo", "text.txt"}},
+		{name: "only matching context two files", arguments: []string{"-o", "-A", "1", "foo", "text.txt", "other.txt"}},
+		{name: "words ignore case", arguments: []string{"-w", "-i", "-n", "foo", "text.txt"}},
+		{name: "unicode words", arguments: []string{"-w", "-i", "-o", "кот", "text.txt"}},
+		{name: "words alternatives", arguments: []string{"-w", "-o", "-n", "-e", "foo", "-e", "foobar", "words.txt"}},
+		{name: "fixed words alternatives", arguments: []string{"-F", "-w", "-o", "-n", "-e", "foo b", "-e", "foo", "words.txt"}},
+		{name: "whole lines", arguments: []string{"-x", "-e", "bar", "-e", "axb", "text.txt"}},
+		{name: "files with matches", arguments: []string{"-l", "other", "text.txt", "other.txt"}},
+		{name: "files without match", arguments: []string{"-L", "other", "text.txt", "other.txt"}},
+		{name: "files without match all match", arguments: []string{"-L", "other", "other.txt"}},
+		{name: "files without match none match", arguments: []string{"-L", "zzz", "text.txt", "other.txt"}},
+		{name: "several patterns", arguments: []string{"-n", "-e", "baz", "-e", "^qu", "text.txt"}},
+		{name: "fixed only matching", arguments: []string{"-F", "-o", "a.b", "text.txt"}},
+		{name: "count two files", arguments: []string{"-c", "foo", "text.txt", "other.txt"}},
+		{name: "no filename", arguments: []string{"-h", "-n", "foo", "text.txt", "other.txt"}},
+		{name: "no match", arguments: []string{"-n", "-C", "1", "nothing at all", "text.txt"}},
+		{name: "missing file", arguments: []string{"foo", "missing.txt", "other.txt"}},
+		{name: "quiet", arguments: []string{"-q", "foo", "text.txt"}},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join("testdata", "gnu")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if *record {
+		fixtures := make(map[string]gnuFixture, len(testCases))
+		for _, testCase := range testCases {
+			command := exec.Command("grep", testCase.arguments...)
+			command.Env = append(os.Environ(), "LC_ALL=C.UTF-8")
+			output, err := command.Output()
+			var exitError *exec.ExitError
+			if err != nil && !errors.As(err, &exitError) {
+				t.Fatal(err)
+			}
+			fixtures[testCase.name] = gnuFixture{Output: string(output), Status: command.ProcessState.ExitCode()}
+		}
+		data, err := json.MarshalIndent(fixtures, "", "\t")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fixturesPath, append(data, '\n'), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(fixturesPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixtures map[string]gnuFixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			expected, ok := fixtures[testCase.name]
+			if !ok {
+				t.Fatalf("no fixture for %q, run the test with -record", testCase.name)
+			}
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out, errOut bytes.Buffer
+			found, err := Search(nil, &out, &errOut, options)
+			if got := ExitStatus(found, err, options.Quiet); got != expected.Status {
+				t.Errorf("status: got %v, want %v", got, expected.Status)
+			}
+			if got := out.String(); got != expected.Output {
+				t.Errorf("result: got %q, want %q", got, expected.Output)
+			}
+		})
+	}
+}
+
 func TestParseArguments(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -119,6 +1293,46 @@ func TestParseArguments(t *testing.T) {
 			arguments:       []string{"-C", "2", "-i", "-v", "-c", "test."},
 			expectedError:   nil,
 			expectedOptions: NewOptions([]string{}, "(?i)test.", 0, 0, 2, true, true, false),
+		}, {
+			name:            "Bad glob",
+			arguments:       []string{"-r", "--include", "[", "test"},
+			expectedError:   ErrBadGlob,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Bad color",
+			arguments:       []string{"--color=sometimes", "test"},
+			expectedError:   ErrBadColor,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Patterns from -e",
+			arguments:       []string{"-i", "-e", "a.", "-e", "b", "test.txt"},
+			expectedError:   nil,
+			expectedOptions: NewOptions([]string{"test.txt"}, "(?i)(?:a.)|(?:b)", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Missing pattern file",
+			arguments:       []string{"-f", "missing.txt"},
+			expectedError:   os.ErrNotExist,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Perl and fixed",
+			arguments:       []string{"-P", "-F", "test"},
+			expectedError:   ErrConflictingMatchers,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "JSON and count",
+			arguments:       []string{"--json", "-c", "test"},
+			expectedError:   ErrConflictingOutput,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Negative field",
+			arguments:       []string{"--field", "-1", "test"},
+			expectedError:   ErrNegativeField,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
+		}, {
+			name:            "Follow without file",
+			arguments:       []string{"--follow", "test"},
+			expectedError:   ErrFollowFiles,
+			expectedOptions: NewOptions([]string{}, "", 0, 0, 0, false, false, false),
 		}, {
 			name:            "No pattern",
 			arguments:       []string{"-C", "2", "-i", "-v", "-c"},
@@ -132,7 +1346,7 @@ func TestParseArguments(t *testing.T) {
 			got, err := ParseArguments(testCase.arguments)
 
 			if testCase.expectedError != nil {
-				if err == nil || err.Error() != testCase.expectedError.Error() {
+				if !errors.Is(err, testCase.expectedError) {
 					t.Errorf("error: got %v, want %v", err, testCase.expectedError)
 				}
 			} else {
@@ -0,0 +1,142 @@
+package grep
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// Статистика поиска (для --json выводится по каждому файлу и итоговая)
+type searchStats struct {
+	Searches          int   `json:"searches"`
+	SearchesWithMatch int   `json:"searches_with_match"`
+	BytesSearched     int64 `json:"bytes_searched"`
+	MatchedLines      int   `json:"matched_lines"`
+	Matches           int   `json:"matches"`
+}
+
+func (stats *searchStats) add(other searchStats) {
+	stats.Searches += other.Searches
+	stats.SearchesWithMatch += other.SearchesWithMatch
+	stats.BytesSearched += other.BytesSearched
+	stats.MatchedLines += other.MatchedLines
+	stats.Matches += other.Matches
+}
+
+// Событие --json (по одному JSON-объекту на строку, формат близок к ripgrep --json):
+// begin - начало вывода строк файла, match и context - найденная строка и строка контекста,
+// end - конец файла со статистикой, summary - итоговая статистика
+type jsonEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+// Строковые данные: корректный UTF-8 выводится как {"text": ...}, остальное - как {"bytes": base64}
+type jsonData string
+
+func (data jsonData) MarshalJSON() ([]byte, error) {
+	if utf8.ValidString(string(data)) {
+		return json.Marshal(struct {
+			Text string `json:"text"`
+		}{string(data)})
+	}
+	return json.Marshal(struct {
+		Bytes []byte `json:"bytes"`
+	}{[]byte(data)})
+}
+
+type jsonBegin struct {
+	Path jsonData `json:"path"`
+}
+
+// Строка результата (lines - текст строки без символов конца строки)
+type jsonLine struct {
+	Path           jsonData       `json:"path"`
+	Lines          jsonData       `json:"lines"`
+	LineNumber     int            `json:"line_number"`
+	AbsoluteOffset int64          `json:"absolute_offset"`
+	Submatches     []jsonSubmatch `json:"submatches"`
+}
+
+// Совпадение в строке (start и end - смещения в байтах от начала строки) и его группы захвата
+type jsonSubmatch struct {
+	Match  jsonData    `json:"match"`
+	Start  int         `json:"start"`
+	End    int         `json:"end"`
+	Groups []jsonGroup `json:"groups"`
+}
+
+// Группа захвата: если группа не участвовала в совпадении, match - null, start и end равны -1
+type jsonGroup struct {
+	Number int       `json:"number"`
+	Name   string    `json:"name,omitempty"`
+	Match  *jsonData `json:"match"`
+	Start  int       `json:"start"`
+	End    int       `json:"end"`
+}
+
+type jsonEnd struct {
+	Path  jsonData    `json:"path"`
+	Stats searchStats `json:"stats"`
+}
+
+type jsonSummary struct {
+	Stats searchStats `json:"stats"`
+}
+
+// Вывод события одной строкой
+func writeEvent(writer io.Writer, eventType string, data any) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(jsonEvent{Type: eventType, Data: data})
+}
+
+// Вывод строки результата; перед первой строкой файла выводится событие begin
+func (p *printer) printJSONLine(line Line, separator byte) error {
+	if !p.begun {
+		p.begun = true
+		if err := writeEvent(p.writer, "begin", jsonBegin{Path: jsonData(p.path)}); err != nil {
+			return err
+		}
+	}
+	submatches := make([]jsonSubmatch, 0, len(line.Matches))
+	for _, span := range line.Matches {
+		submatch := jsonSubmatch{
+			Match:  jsonData(line.Text[span[0]:span[1]]),
+			Start:  span[0],
+			End:    span[1],
+			Groups: make([]jsonGroup, 0, len(span)/2-1),
+		}
+		for i := 2; i+1 < len(span); i += 2 {
+			group := jsonGroup{Number: i / 2, Start: span[i], End: span[i+1]}
+			if i/2 < len(p.groupNames) {
+				group.Name = p.groupNames[i/2]
+			}
+			if span[i] >= 0 {
+				text := jsonData(line.Text[span[i]:span[i+1]])
+				group.Match = &text
+			}
+			submatch.Groups = append(submatch.Groups, group)
+		}
+		submatches = append(submatches, submatch)
+	}
+	eventType := "match"
+	if separator == '-' {
+		eventType = "context"
+	}
+	return writeEvent(p.writer, eventType, jsonLine{
+		Path:           jsonData(p.path),
+		Lines:          jsonData(line.Text),
+		LineNumber:     line.Number,
+		AbsoluteOffset: line.Offset,
+		Submatches:     submatches,
+	})
+}
+
+// Вывод события end, если выводились строки файла
+func (p *printer) printJSONEnd() error {
+	if !p.begun {
+		return nil
+	}
+	return writeEvent(p.writer, "end", jsonEnd{Path: jsonData(p.path), Stats: p.stats})
+}
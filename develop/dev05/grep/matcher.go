@@ -0,0 +1,282 @@
+package grep
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Поиск совпадений в строке (реализуется *regexp.Regexp и автоматом Ахо-Корасик)
+type Matcher interface {
+	// Проверка наличия совпадения в строке
+	MatchString(s string) bool
+	// Позиции n первых непересекающихся совпадений (n < 0 - всех), nil - совпадений нет
+	FindAllStringIndex(s string, n int) [][]int
+}
+
+var ErrUnknownGroup error = errors.New("no such capture group")
+
+// Matcher, находящий позиции групп захвата (реализуется *regexp.Regexp)
+type submatchMatcher interface {
+	// Позиции совпадений вместе с группами захвата: пары начало-конец (-1 - группа не участвовала в совпадении)
+	FindAllStringSubmatchIndex(s string, n int) [][]int
+	// Имена групп (нулевой элемент соответствует всему совпадению, у групп без имени - пустая строка)
+	SubexpNames() []string
+}
+
+// Matcher, поиск которым может завершиться ошибкой (например, превышением ограничения времени)
+type limitedMatcher interface {
+	MatchStringLimited(s string) (bool, error)
+	FindAllStringIndexLimited(s string, n int) ([][]int, error)
+	FindAllStringSubmatchIndexLimited(s string, n int) ([][]int, error)
+}
+
+// Имена групп захвата Matcher (у фиксированных строк групп нет)
+func subexpNames(matcher Matcher) []string {
+	if submatch, ok := matcher.(submatchMatcher); ok {
+		return submatch.SubexpNames()
+	}
+	return []string{""}
+}
+
+// Номер группы захвата по имени или номеру (пустая строка - всё совпадение)
+func groupIndex(matcher Matcher, group string) (int, error) {
+	if group == "" {
+		return 0, nil
+	}
+	names := subexpNames(matcher)
+	if index, err := strconv.Atoi(group); err == nil {
+		if index < 0 || index >= len(names) {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownGroup, group)
+		}
+		return index, nil
+	}
+	for index, name := range names {
+		if name == group {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnknownGroup, group)
+}
+
+// Поле field строки (нумерация с 1), поля разделены delimiter; возвращается и смещение поля в строке.
+// Если полей меньше - поле пустое и находится в конце строки
+func selectField(text string, field int, delimiter string) (string, int) {
+	start := 0
+	for i := 1; i < field; i++ {
+		index := strings.Index(text[start:], delimiter)
+		if index < 0 {
+			return "", len(text)
+		}
+		start += index + len(delimiter)
+	}
+	if end := strings.Index(text[start:], delimiter); end >= 0 {
+		return text[start : start+end], start
+	}
+	return text[start:], start
+}
+
+// Поиск совпадений в строке: позиции вычисляются только при необходимости (needSpans),
+// позиции групп захвата - только с needGroups
+func matchLine(matcher Matcher, text string, needSpans, needGroups bool) (bool, [][]int, error) {
+	if limited, ok := matcher.(limitedMatcher); ok {
+		switch {
+		case needGroups:
+			spans, err := limited.FindAllStringSubmatchIndexLimited(text, -1)
+			return spans != nil, spans, err
+		case needSpans:
+			spans, err := limited.FindAllStringIndexLimited(text, -1)
+			return spans != nil, spans, err
+		}
+		match, err := limited.MatchStringLimited(text)
+		return match, nil, err
+	}
+	if submatch, ok := matcher.(submatchMatcher); ok && needGroups {
+		spans := submatch.FindAllStringSubmatchIndex(text, -1)
+		return spans != nil, spans, nil
+	}
+	if needSpans || needGroups {
+		spans := matcher.FindAllStringIndex(text, -1)
+		return spans != nil, spans, nil
+	}
+	return matcher.MatchString(text), nil, nil
+}
+
+// Поиск совпадений только в поле options.Field строки (0 - во всей строке); позиции совпадений
+// отсчитываются от начала строки
+func matchField(matcher Matcher, text string, options Options, needSpans, needGroups bool) (bool, [][]int, error) {
+	if options.Field == 0 {
+		return matchLine(matcher, text, needSpans, needGroups)
+	}
+	field, start := selectField(text, options.Field, options.Delimiter)
+	match, spans, err := matchLine(matcher, field, needSpans, needGroups)
+	for _, span := range spans {
+		for i := range span {
+			if span[i] >= 0 {
+				span[i] += start
+			}
+		}
+	}
+	return match, spans, err
+}
+
+// Создание Matcher по параметрам: для фиксированных строк - автомат Ахо-Корасик, для -P - движок
+// с возвратами, иначе - регулярное выражение RE2 options.Pattern (для -x шаблон уже ограничен
+// началом и концом строки, для -P - и границами слов при -w)
+func NewMatcher(options Options) (Matcher, error) {
+	if options.Perl {
+		return NewBacktrackMatcher(options.Pattern, options.PerlTimeout)
+	}
+	if options.Fixed && len(options.Patterns) > 0 {
+		automaton := NewAhoCorasick(options.Patterns, options.IgnoreCase)
+		switch {
+		case options.LineRegexp:
+			return lineMatcher{automaton}, nil
+		case options.Word:
+			// Автомат и так находит самые левые самые длинные совпадения
+			return wordMatcher{matcher: automaton, longest: automaton}, nil
+		}
+		return automaton, nil
+	}
+	compiled, err := regexp.Compile(options.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !options.Word || options.LineRegexp {
+		return compiled, nil
+	}
+	// Совпадения, не ограниченные границами слова, перебираются от самых длинных к коротким (как в GNU grep)
+	// отдельной копией выражения, чтобы не менять совпадения и группы захвата самого шаблона
+	longest, err := regexp.Compile(options.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	longest.Longest()
+	return wordMatcher{matcher: compiled, longest: longest}, nil
+}
+
+// Совпадения только со строкой целиком (-x для фиксированных строк: самое левое самое длинное
+// совпадение должно занимать всю строку)
+type lineMatcher struct {
+	matcher Matcher
+}
+
+func (matcher lineMatcher) MatchString(s string) bool {
+	return matcher.FindAllStringIndex(s, 1) != nil
+}
+
+func (matcher lineMatcher) FindAllStringIndex(s string, n int) [][]int {
+	spans := matcher.matcher.FindAllStringIndex(s, 1)
+	if n == 0 || len(spans) == 0 || spans[0][0] != 0 || spans[0][1] != len(s) {
+		return nil
+	}
+	return spans
+}
+
+// Совпадения только с целыми словами (-w): до и после совпадения должен быть конец строки
+// или символ, не входящий в слово
+type wordMatcher struct {
+	matcher Matcher
+	// Тот же шаблон с поиском самых левых самых длинных совпадений (для повторного поиска с тем же началом)
+	longest Matcher
+}
+
+// Проверка, является ли символ частью слова (буквы и цифры Unicode, знак подчёркивания)
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func (matcher wordMatcher) MatchString(s string) bool {
+	return matcher.FindAllStringIndex(s, 1) != nil
+}
+
+func (matcher wordMatcher) FindAllStringIndex(s string, n int) [][]int {
+	return matcher.findAll(s, n, matcher.matcher.FindAllStringIndex, matcher.longest.FindAllStringIndex)
+}
+
+func (matcher wordMatcher) FindAllStringSubmatchIndex(s string, n int) [][]int {
+	submatch, ok := matcher.matcher.(submatchMatcher)
+	longest, longestOk := matcher.longest.(submatchMatcher)
+	if !ok || !longestOk {
+		return matcher.FindAllStringIndex(s, n)
+	}
+	return matcher.findAll(s, n, submatch.FindAllStringSubmatchIndex, longest.FindAllStringSubmatchIndex)
+}
+
+func (matcher wordMatcher) SubexpNames() []string {
+	return subexpNames(matcher.matcher)
+}
+
+// Поиск совпадений функцией find: если совпадение не ограничено границами слова, функцией retry
+// (самые левые самые длинные совпадения) проверяются самое длинное и более короткие совпадения с тем же
+// началом, затем поиск продолжается со следующего символа
+func (matcher wordMatcher) findAll(s string, n int, find, retry func(s string, n int) [][]int) [][]int {
+	var result [][]int
+	for position := 0; position <= len(s) && (n < 0 || len(result) < n); {
+		spans := find(s[position:], 1)
+		if spans == nil {
+			break
+		}
+		span := shiftSpan(spans[0], position)
+		start := span[0]
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		if (start == 0 || !isWordRune(before)) && !isWordEnd(s, span[1]) {
+			// Самое длинное совпадение с этим началом, затем совпадения в строке, укороченной на последний
+			// символ предыдущего
+			if longest := retry(s[start:], 1); longest != nil && longest[0][0] == 0 {
+				span = shiftSpan(longest[0], start)
+			}
+		}
+		if start == 0 || !isWordRune(before) {
+			for span != nil && !isWordEnd(s, span[1]) {
+				if span[1] == start {
+					span = nil
+					break
+				}
+				_, size := utf8.DecodeLastRuneInString(s[start:span[1]])
+				shorter := retry(s[start:span[1]-size], 1)
+				if shorter == nil || shorter[0][0] != 0 {
+					span = nil
+					break
+				}
+				span = shiftSpan(shorter[0], start)
+			}
+			if span != nil {
+				result = append(result, span)
+				if span[1] > start {
+					position = span[1]
+					continue
+				}
+			}
+		}
+		// Переход к следующему символу после начала неподходящего (или пустого) совпадения
+		if start == len(s) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[start:])
+		position = start + size
+	}
+	return result
+}
+
+// Проверка, что позиция end - конец строки или за ней идёт символ, не входящий в слово
+func isWordEnd(s string, end int) bool {
+	after, _ := utf8.DecodeRuneInString(s[end:])
+	return end == len(s) || !isWordRune(after)
+}
+
+// Перевод позиций совпадения (в том числе групп захвата) из подстроки, начинающейся с offset, в строку
+func shiftSpan(span []int, offset int) []int {
+	shifted := make([]int, len(span))
+	for i, index := range span {
+		shifted[i] = index
+		if index >= 0 {
+			shifted[i] += offset
+		}
+	}
+	return shifted
+}
@@ -4,32 +4,106 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
+	"time"
 )
 
 var ErrNotEnoughArguments error = errors.New("not enough arguments")
+var ErrNonPositiveJobs error = errors.New("number of jobs must be a positive number")
+var ErrBadColor error = errors.New("color mode must be one of: never, always, auto")
+var ErrConflictingMatchers error = errors.New("conflicting matchers specified: -P and -F")
+var ErrConflictingOutput error = errors.New("--json cannot be used with -c, -l or -L")
+var ErrNegativeField error = errors.New("field number must not be negative")
+var ErrEmptyDelimiter error = errors.New("field delimiter must not be empty")
+var ErrNonPositiveTimeout error = errors.New("-P time limit must be positive")
+var ErrBadGlob error = errors.New("invalid file name pattern")
+
+// Регулярные выражения (RE2 и -P), под которые не подходит ни одна строка
+const (
+	noMatchPattern     = `[^\x00-\x{10FFFF}]`
+	perlNoMatchPattern = `(?!)`
+)
+
+// Разделитель групп строк контекста по умолчанию
+const DefaultGroupSeparator = "--"
+
+// Разделитель полей для --field по умолчанию
+const DefaultDelimiter = "\t"
+
+// Ограничение времени поиска совпадений в одной строке для -P по умолчанию
+const DefaultPerlTimeout = time.Second
 
 type Options struct {
-	Filepaths []string
-	Pattern   string
-	After     int
-	Before    int
-	Context   int
-	Count     bool
-	Invert    bool
-	LineNum   bool
+	Filepaths         []string
+	Pattern           string
+	After             int
+	Before            int
+	Context           int
+	Count             bool
+	Invert            bool
+	LineNum           bool
+	Recursive         bool
+	Dereference       bool
+	Include           []string
+	Exclude           []string
+	ExcludeDir        []string
+	WithFilename      bool
+	NoFilename        bool
+	Jobs              int
+	GitIgnore         bool
+	OnlyMatching      bool
+	ByteOffset        bool
+	Color             string
+	Patterns          []string
+	Fixed             bool
+	IgnoreCase        bool
+	Word              bool
+	LineRegexp        bool
+	FilesWithMatches  bool
+	FilesWithoutMatch bool
+	MaxCount          int
+	Quiet             bool
+	Perl              bool
+	PerlTimeout       time.Duration
+	GroupSeparator    string
+	NoGroupSeparator  bool
+	Decompress        bool
+	JSON              bool
+	Field             int
+	Delimiter         string
+	OnlyGroup         string
+	Follow            bool
+}
+
+// Значение флага, который может быть указан несколько раз
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
 }
 
 func NewOptions(filepaths []string, pattern string, after, before, context int, count, invert, lineNum bool) Options {
 	return Options{
-		Filepaths: filepaths,
-		Pattern:   pattern,
-		After:     after,
-		Before:    before,
-		Context:   context,
-		Count:     count,
-		Invert:    invert,
-		LineNum:   lineNum,
+		Filepaths:      filepaths,
+		Pattern:        pattern,
+		After:          after,
+		Before:         before,
+		Context:        context,
+		Count:          count,
+		Invert:         invert,
+		LineNum:        lineNum,
+		MaxCount:       -1,
+		PerlTimeout:    DefaultPerlTimeout,
+		GroupSeparator: DefaultGroupSeparator,
+		Delimiter:      DefaultDelimiter,
 	}
 }
 
@@ -44,26 +118,187 @@ func ParseArguments(arguments []string) (Options, error) {
 	invert := fSet.Bool("v", false, "instead of matching, exclude")
 	fixed := fSet.Bool("F", false, "exact match to string, not a pattern")
 	lineNum := fSet.Bool("n", false, "print line number")
+	recursive := fSet.Bool("r", false, "search directories recursively")
+	dereference := fSet.Bool("R", false, "search directories recursively, following all symbolic links")
+	withFilename := fSet.Bool("H", false, "print file name with output lines")
+	noFilename := fSet.Bool("h", false, "suppress file name prefix on output")
+	jobs := fSet.Int("j", 1, "number of files searched in parallel")
+	gitIgnore := fSet.Bool("gitignore", false, "skip files and directories ignored by .gitignore in recursive search")
+	onlyMatching := fSet.Bool("o", false, "print only matched parts of lines")
+	byteOffset := fSet.Bool("b", false, "print byte offset with output lines")
+	color := fSet.String("color", ColorNever, "highlight matches: never, always or auto")
+	word := fSet.Bool("w", false, "match only whole words")
+	lineRegexp := fSet.Bool("x", false, "match only whole lines")
+	filesWithMatches := fSet.Bool("l", false, "print only names of files with matches")
+	filesWithoutMatch := fSet.Bool("L", false, "print only names of files without matches")
+	maxCount := fSet.Int("m", -1, "stop reading a file after NUM matching lines")
+	quiet := fSet.Bool("q", false, "quiet mode: exit immediately with zero status if any match is found")
+	perl := fSet.Bool("P", false, "PATTERNS are Perl-like regular expressions (backreferences, lookaround)")
+	perlTimeout := fSet.Duration("perl-timeout", DefaultPerlTimeout, "time limit for matching one line with -P")
+	groupSeparator := fSet.String("group-separator", DefaultGroupSeparator, "print SEP between groups of context lines")
+	noGroupSeparator := fSet.Bool("no-group-separator", false, "do not print separator between groups of context lines")
+	decompress := fSet.Bool("z", false, "search in compressed input: gzip, bzip2 and zstd are detected by magic bytes")
+	jsonOutput := fSet.Bool("json", false, "print results as newline-delimited JSON events")
+	field := fSet.Int("field", 0, "match only in field N of the line (fields are numbered from 1)")
+	delimiter := fSet.String("delimiter", DefaultDelimiter, "use DELIM as field delimiter for --field")
+	onlyGroup := fSet.String("only-group", "", "print only capture group NAME or NUMBER of matches (implies -o)")
+	follow := fSet.Bool("follow", false, "keep reading FILE as it grows, reopening it after rotation or truncation")
+	var include, exclude, excludeDir, expressions, patternFiles stringList
+	fSet.Var(&expressions, "e", "use PATTERN for matching (can be repeated)")
+	fSet.Var(&patternFiles, "f", "take patterns from FILE, one per line (can be repeated)")
+	fSet.Var(&include, "include", "search only files whose base name matches GLOB")
+	fSet.Var(&exclude, "exclude", "skip files whose base name matches GLOB")
+	fSet.Var(&excludeDir, "exclude-dir", "skip directories whose base name matches GLOB")
 
 	if err := fSet.Parse(arguments); err != nil {
 		return Options{}, err
 	}
 
-	if len(fSet.Args()) < 1 {
-		return Options{}, ErrNotEnoughArguments
+	if *jobs < 1 {
+		return Options{}, ErrNonPositiveJobs
+	}
+
+	switch *color {
+	case ColorNever, ColorAlways, ColorAuto:
+	default:
+		return Options{}, ErrBadColor
+	}
+
+	// Шаблоны из -e и -f; если их нет - шаблоном является первый аргумент
+	patterns := []string(expressions)
+	for _, path := range patternFiles {
+		filePatterns, err := readPatterns(path)
+		if err != nil {
+			return Options{}, err
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	filenames := fSet.Args()
+	if len(expressions) == 0 && len(patternFiles) == 0 {
+		if len(fSet.Args()) < 1 {
+			return Options{}, ErrNotEnoughArguments
+		}
+		patterns = []string{fSet.Arg(0)}
+		filenames = fSet.Args()[1:]
+	}
+
+	// Проверка корректности шаблонов имён файлов
+	for _, globs := range [][]string{include, exclude, excludeDir} {
+		for _, glob := range globs {
+			if _, err := filepath.Match(glob, ""); err != nil {
+				return Options{}, fmt.Errorf("%w: %s", ErrBadGlob, glob)
+			}
+		}
 	}
 
-	pattern := fSet.Arg(0)
-	filenames := fSet.Args()[1:]
+	if *perl && *fixed {
+		return Options{}, ErrConflictingMatchers
+	}
+	if *jsonOutput && (*count || *filesWithMatches || *filesWithoutMatch) {
+		return Options{}, ErrConflictingOutput
+	}
+	// Следить можно только за одним файлом (не за стандартным вводом и не за директорией)
+	if *follow && (len(filenames) != 1 || filenames[0] == "-" || *recursive || *dereference) {
+		return Options{}, ErrFollowFiles
+	}
+	if *field < 0 {
+		return Options{}, ErrNegativeField
+	}
+	if *delimiter == "" {
+		return Options{}, ErrEmptyDelimiter
+	}
+	if *perlTimeout <= 0 {
+		return Options{}, ErrNonPositiveTimeout
+	}
+
+	options := NewOptions(filenames, "", *after, *before, *context, *count, *invert, *lineNum)
+	options.Recursive = *recursive || *dereference
+	options.Dereference = *dereference
+	options.Include = include
+	options.Exclude = exclude
+	options.ExcludeDir = excludeDir
+	// Из взаимоисключающих -H и -h действует флаг -h
+	options.WithFilename = *withFilename && !*noFilename
+	options.NoFilename = *noFilename
+	options.Jobs = *jobs
+	options.GitIgnore = *gitIgnore
+	options.OnlyMatching = *onlyMatching || *onlyGroup != ""
+	options.ByteOffset = *byteOffset
+	options.Color = *color
+	options.Patterns = patterns
+	options.Fixed = *fixed
+	options.IgnoreCase = *ignoreCase
+	options.Word = *word
+	options.LineRegexp = *lineRegexp
+	options.FilesWithMatches = *filesWithMatches
+	options.FilesWithoutMatch = *filesWithoutMatch
+	options.MaxCount = *maxCount
+	options.Quiet = *quiet
+	options.Perl = *perl
+	options.PerlTimeout = *perlTimeout
+	options.GroupSeparator = *groupSeparator
+	options.NoGroupSeparator = *noGroupSeparator
+	options.Decompress = *decompress
+	options.JSON = *jsonOutput
+	options.Field = *field
+	options.Delimiter = *delimiter
+	options.OnlyGroup = *onlyGroup
+	options.Follow = *follow
+	options.Pattern = joinPatterns(patterns, options)
+	return options, nil
+}
 
-	// Если не паттерн - экранирование символов
-	if *fixed {
-		pattern = regexp.QuoteMeta(pattern)
+// Чтение шаблонов из файла (по одному в строке)
+func readPatterns(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	patterns := strings.Split(text, "\n")
+	for i, pattern := range patterns {
+		patterns[i] = strings.TrimSuffix(pattern, "\r")
+	}
+	return patterns, nil
+}
+
+// Объединение шаблонов в одно регулярное выражение (строки подходят, если подходят хотя бы под один шаблон)
+// с учётом -F, -i, -x, а для -P - и -w (для RE2 границы слов проверяет wordMatcher)
+func joinPatterns(patterns []string, options Options) string {
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		// Если не паттерн - экранирование символов
+		if options.Fixed {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		quoted[i] = pattern
+	}
+
+	var pattern string
+	switch len(quoted) {
+	case 0:
+		// Без шаблонов (пустой файл -f) не подходит ни одна строка
+		if options.Perl {
+			return perlNoMatchPattern
+		}
+		return noMatchPattern
+	case 1:
+		pattern = quoted[0]
+	default:
+		pattern = "(?:" + strings.Join(quoted, ")|(?:") + ")"
+	}
+	switch {
+	case options.LineRegexp:
+		pattern = "^(?:" + pattern + ")$"
+	case options.Word && options.Perl:
+		pattern = `(?<![\p{L}\p{N}_])(?:` + pattern + `)(?![\p{L}\p{N}_])`
 	}
 	// Если игнорирование регистра - добавление соответствующего модификатора
-	if *ignoreCase {
+	if options.IgnoreCase {
 		pattern = fmt.Sprintf("(?i)%s", pattern)
 	}
-
-	return NewOptions(filenames, pattern, *after, *before, *context, *count, *invert, *lineNum), nil
+	return pattern
 }
@@ -0,0 +1,83 @@
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Задание поиска в одном файле: результат накапливается в буфере до вывода
+type searchJob struct {
+	path   string
+	output bytes.Buffer
+	found  bool
+	// Выведены ли строки (перед ними может понадобиться разделитель групп)
+	started bool
+	stats   searchStats
+	err     error
+	done    chan struct{}
+}
+
+// Параллельный поиск по файлам пулом из options.Jobs потоков; результаты файлов выводятся целиком
+// и в порядке обхода, число одновременно хранимых результатов ограничено, статистика поиска добавляется
+// к stats. Возвращает, найдено ли совпадение
+func searchParallel(stdin io.Reader, outWriter *bufio.Writer, reportError func(error), stats *searchStats, matcher Matcher, color bool, options Options) bool {
+	showFilename := withFilename(options)
+	jobs := make(chan *searchJob)
+	ordered := make(chan *searchJob, 2*options.Jobs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < options.Jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				writer := bufio.NewWriter(&job.output)
+				p := newPrinter(writer, options, color)
+				job.found, job.err = searchPath(stdin, job.path, p, showFilename, matcher, options)
+				job.started = *p.started
+				job.stats = p.stats
+				writer.Flush()
+				close(job.done)
+			}
+		}()
+	}
+
+	// Обход файлов: каждое задание (и ошибка обхода) ставится в очередь вывода до передачи в пул
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		walkFiles(options, func(path string) bool {
+			job := &searchJob{path: path, done: make(chan struct{})}
+			ordered <- job
+			jobs <- job
+			return true
+		}, func(err error) {
+			job := &searchJob{err: err, done: make(chan struct{})}
+			close(job.done)
+			ordered <- job
+		})
+	}()
+
+	found := false
+	started := false
+	separator := newPrinter(outWriter, options, color)
+	for job := range ordered {
+		<-job.done
+		// Первая группа строк файла отделяется от строк предыдущих файлов
+		if job.started && started && separator.separateGroups {
+			separator.writeColored(separator.groupSeparator, sgrSeparator)
+			outWriter.WriteByte('\n')
+		}
+		started = started || job.started
+		outWriter.Write(job.output.Bytes())
+		if job.err != nil {
+			reportError(job.err)
+		}
+		found = found || job.found
+		stats.add(job.stats)
+	}
+	wg.Wait()
+	return found
+}
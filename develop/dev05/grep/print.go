@@ -0,0 +1,160 @@
+package grep
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+)
+
+// Режимы подсветки совпадений (--color)
+const (
+	ColorNever  = "never"
+	ColorAlways = "always"
+	ColorAuto   = "auto"
+)
+
+// SGR-последовательности подсветки (значения по умолчанию GNU grep)
+const (
+	sgrEnd       = "\x1b[m\x1b[K"
+	sgrMatch     = "\x1b[01;31m\x1b[K"
+	sgrFilename  = "\x1b[35m\x1b[K"
+	sgrNumber    = "\x1b[32m\x1b[K"
+	sgrSeparator = "\x1b[36m\x1b[K"
+)
+
+// Вывод строк результата
+type printer struct {
+	writer *bufio.Writer
+	// Имя файла для префикса строк (пустое - без префикса)
+	filename     string
+	lineNum      bool
+	byteOffset   bool
+	onlyMatching bool
+	color        bool
+	// Номер выводимой с -o группы захвата (0 - всё совпадение)
+	group int
+	// Разделитель несмежных групп строк (выводится только при выводе строк контекста)
+	groupSeparator string
+	separateGroups bool
+	// Номер последней выведенной строки файла (0 - строки файла ещё не выводились)
+	lastNumber int
+	// Выводились ли строки ранее (общий флаг для всех файлов, чтобы разделять группы разных файлов)
+	started *bool
+	// Вывод событий JSON (--json): путь к файлу, имена групп захвата, выводилось ли событие begin
+	// и статистика поиска в файле
+	json       bool
+	path       string
+	groupNames []string
+	begun      bool
+	stats      searchStats
+}
+
+func newPrinter(writer *bufio.Writer, options Options, color bool) *printer {
+	context := max(options.After, options.Before, options.Context) > 0
+	return &printer{
+		writer:         writer,
+		lineNum:        options.LineNum,
+		byteOffset:     options.ByteOffset,
+		onlyMatching:   options.OnlyMatching,
+		color:          color,
+		groupSeparator: options.GroupSeparator,
+		separateGroups: context && !options.NoGroupSeparator,
+		started:        new(bool),
+		json:           options.JSON,
+	}
+}
+
+// Проверка, нужна ли подсветка: с --color=auto - только при выводе в терминал
+func colorEnabled(out any, when string) bool {
+	switch when {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		file, ok := out.(*os.File)
+		if !ok {
+			return false
+		}
+		info, err := file.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+	}
+	return false
+}
+
+// Вывод строки с подсветкой (если она включена)
+func (p *printer) writeColored(str, sgr string) {
+	if p.color {
+		p.writer.WriteString(sgr)
+		p.writer.WriteString(str)
+		p.writer.WriteString(sgrEnd)
+		return
+	}
+	p.writer.WriteString(str)
+}
+
+// Вывод префикса строки: имя файла, номер строки и смещение в байтах, разделённые separator
+func (p *printer) printPrefix(line Line, offset int64, separator byte) {
+	fields := make([]string, 0, 3)
+	colors := make([]string, 0, 3)
+	if p.filename != "" {
+		fields, colors = append(fields, p.filename), append(colors, sgrFilename)
+	}
+	if p.lineNum {
+		fields, colors = append(fields, strconv.Itoa(line.Number)), append(colors, sgrNumber)
+	}
+	if p.byteOffset {
+		fields, colors = append(fields, strconv.FormatInt(offset, 10)), append(colors, sgrNumber)
+	}
+	for i, field := range fields {
+		p.writeColored(field, colors[i])
+		p.writeColored(string(separator), sgrSeparator)
+	}
+}
+
+// Вывод строки (найденные строки при выводе имени файла и номера имеют вид file:N:content, контекст - file-N-content);
+// с -o выводятся только совпавшие части найденной строки, каждая на отдельной строке, с --json - событие JSON
+func (p *printer) printLine(line Line, separator byte) error {
+	if p.json {
+		return p.printJSONLine(line, separator)
+	}
+	// Если строка не продолжает предыдущую группу (или это первая группа файла после строк других файлов) -
+	// вывод разделителя групп (с -o строки контекста не выводятся, но группы разделяются, как в GNU grep)
+	if p.separateGroups && ((p.lastNumber == 0 && *p.started) || (p.lastNumber != 0 && line.Number != p.lastNumber+1)) {
+		p.writeColored(p.groupSeparator, sgrSeparator)
+		p.writer.WriteByte('\n')
+	}
+	p.lastNumber = line.Number
+	*p.started = true
+	if p.onlyMatching {
+		return p.printMatches(line)
+	}
+	p.printPrefix(line, line.Offset, separator)
+	last := 0
+	if p.color {
+		for _, span := range line.Matches {
+			p.writer.WriteString(line.Text[last:span[0]])
+			if span[0] < span[1] {
+				p.writeColored(line.Text[span[0]:span[1]], sgrMatch)
+			}
+			last = span[1]
+		}
+	}
+	p.writer.WriteString(line.Text[last:])
+	return p.writer.WriteByte('\n')
+}
+
+// Вывод совпавших частей строки или группы захвата p.group (пустые совпадения и не участвовавшие
+// в совпадении группы пропускаются)
+func (p *printer) printMatches(line Line) error {
+	for _, span := range line.Matches {
+		start, end := span[2*p.group], span[2*p.group+1]
+		if start == end {
+			continue
+		}
+		p.printPrefix(line, line.Offset+int64(start), ':')
+		p.writeColored(line.Text[start:end], sgrMatch)
+		if err := p.writer.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
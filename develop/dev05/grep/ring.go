@@ -0,0 +1,51 @@
+package grep
+
+// Строка входного текста с номером (нумерация с 1), смещением начала строки в байтах
+// и позициями совпадений (пары индексов начала и конца, как в regexp.FindAllStringIndex)
+type Line struct {
+	Number  int
+	Offset  int64
+	Text    string
+	Matches [][]int
+}
+
+// Кольцевой буфер последних строк (для вывода контекста перед совпадением)
+type ringBuffer struct {
+	lines []Line
+	start int
+	size  int
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{lines: make([]Line, capacity)}
+}
+
+// Добавление строки в буфер (при заполнении вытесняется самая старая строка)
+func (buffer *ringBuffer) Push(line Line) {
+	capacity := len(buffer.lines)
+	if capacity == 0 {
+		return
+	}
+	if buffer.size < capacity {
+		buffer.lines[(buffer.start+buffer.size)%capacity] = line
+		buffer.size++
+		return
+	}
+	buffer.lines[buffer.start] = line
+	buffer.start = (buffer.start + 1) % capacity
+}
+
+// Извлечение всех строк буфера в порядке добавления с очисткой буфера
+func (buffer *ringBuffer) Drain(process func(Line) error) error {
+	capacity := len(buffer.lines)
+	for buffer.size > 0 {
+		line := buffer.lines[buffer.start]
+		buffer.start = (buffer.start + 1) % capacity
+		buffer.size--
+		if err := process(line); err != nil {
+			return err
+		}
+	}
+	buffer.start = 0
+	return nil
+}
@@ -0,0 +1,247 @@
+package grep
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrIsDirectory error = errors.New("is a directory")
+
+// Имя, под которым выводится стандартный ввод
+const StdinName = "(standard input)"
+
+// Текст ошибки доступа к файлу в формате GNU grep: "файл: Описание ошибки" (без названия системного
+// вызова, описание - с заглавной буквы, как у strerror)
+func errorMessage(err error) string {
+	var pathError *fs.PathError
+	if !errors.As(err, &pathError) {
+		return err.Error()
+	}
+	message := pathError.Err.Error()
+	first, size := utf8.DecodeRuneInString(message)
+	return pathError.Path + ": " + string(unicode.ToUpper(first)) + message[size:]
+}
+
+// Проверка совпадения имени файла (или его базового имени) хотя бы с одним шаблоном
+func matchesAny(globs []string, path string) bool {
+	base := filepath.Base(path)
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, base); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(glob, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Проверка, нужно ли искать в файле с учетом --include и --exclude
+func includedFile(path string, options Options) bool {
+	if len(options.Include) > 0 && !matchesAny(options.Include, path) {
+		return false
+	}
+	return !matchesAny(options.Exclude, path)
+}
+
+// Проверка, выводятся ли имена файлов: по умолчанию имена выводятся, если файлов несколько
+// или поиск выполняется рекурсивно по директории
+func withFilename(options Options) bool {
+	switch {
+	case options.NoFilename:
+		return false
+	case options.WithFilename, len(options.Filepaths) > 1:
+		return true
+	case !options.Recursive:
+		return false
+	case len(options.Filepaths) == 0:
+		return true
+	}
+	info, err := os.Stat(options.Filepaths[0])
+	return err == nil && info.IsDir()
+}
+
+// Обход файлов для поиска: visit вызывается для каждого файла в порядке обхода ("-" - стандартный ввод)
+// и может прервать обход, вернув false; ошибки доступа к файлам передаются в onError и не прерывают обход
+func walkFiles(options Options, visit func(path string) bool, onError func(error)) {
+	paths := options.Filepaths
+	if len(paths) == 0 {
+		// Без -r читается стандартный ввод, с -r - текущая директория
+		paths = []string{"-"}
+		if options.Recursive {
+			paths = []string{"."}
+		}
+	}
+
+	for _, path := range paths {
+		if path == "-" {
+			if !visit(path) {
+				return
+			}
+			continue
+		}
+		// Символические ссылки, указанные в аргументах, разыменовываются всегда
+		info, err := os.Stat(path)
+		if err != nil {
+			onError(err)
+			continue
+		}
+		if !info.IsDir() {
+			if includedFile(path, options) && !visit(path) {
+				return
+			}
+			continue
+		}
+		if !options.Recursive {
+			onError(&fs.PathError{Op: "read", Path: path, Err: ErrIsDirectory})
+			continue
+		}
+		walker := directoryWalker{options: options, visit: visit, onError: onError, visited: make(map[string]struct{})}
+		var rules []ignoreRule
+		if options.GitIgnore {
+			if rules, err = ancestorIgnoreRules(path); err != nil {
+				onError(err)
+			}
+		}
+		// Пути файлов выводятся от указанной директории (без аргументов - от текущей директории без "./")
+		display := path
+		if len(options.Filepaths) == 0 {
+			display = ""
+		}
+		if !walker.walk(path, display, rules) {
+			return
+		}
+	}
+}
+
+// Рекурсивный обход директории
+type directoryWalker struct {
+	options Options
+	visit   func(path string) bool
+	onError func(error)
+	// Обход прерван функцией visit
+	stopped bool
+	// Пройденные директории (для -R, чтобы не зациклиться на символических ссылках)
+	visited map[string]struct{}
+}
+
+// Замена пути в ошибке доступа к файлу на выводимый путь
+func displayError(err error, path string) error {
+	var pathError *fs.PathError
+	if errors.As(err, &pathError) {
+		pathError.Path = path
+	}
+	return err
+}
+
+// Обход директории root, выводимой как display (пути файлов выводятся так, как указана директория,
+// как в GNU grep: "." - "./a.txt", "dir/" - "dir/a.txt"); inherited - правила .gitignore, действующие
+// в root (с --gitignore). Возвращает false, если обход прерван
+func (walker *directoryWalker) walk(root, display string, inherited []ignoreRule) bool {
+	prefix := display
+	if prefix != "" {
+		prefix = strings.TrimRight(prefix, "/") + "/"
+	}
+	show := func(path string) string {
+		relative, err := filepath.Rel(root, path)
+		if err != nil || relative == "." {
+			return display
+		}
+		return prefix + filepath.ToSlash(relative)
+	}
+
+	if walker.options.Dereference {
+		real, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			walker.onError(err)
+			return true
+		}
+		if _, ok := walker.visited[real]; ok {
+			return true
+		}
+		walker.visited[real] = struct{}{}
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		walker.onError(err)
+		return true
+	}
+	// Правила .gitignore, действующие в каждой пройденной директории
+	rules := map[string][]ignoreRule{}
+	// Проверка, игнорируется ли файл правилами .gitignore директории, в которой он находится
+	ignored := func(path string, isDir bool) bool {
+		if !walker.options.GitIgnore || path == root {
+			return false
+		}
+		if isDir && filepath.Base(path) == gitDirName {
+			return true
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return false
+		}
+		return isIgnored(rules[filepath.Dir(path)], filepath.Join(absRoot, relative), isDir)
+	}
+
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			walker.onError(displayError(err, show(path)))
+			return nil
+		}
+		if walker.stopped {
+			return filepath.SkipAll
+		}
+		switch {
+		case entry.IsDir():
+			if path != root && matchesAny(walker.options.ExcludeDir, path) {
+				return filepath.SkipDir
+			}
+			if ignored(path, true) {
+				return filepath.SkipDir
+			}
+			if walker.options.GitIgnore {
+				parentRules := inherited
+				if path != root {
+					parentRules = rules[filepath.Dir(path)]
+				}
+				dirRules, err := readIgnoreFile(path)
+				if err != nil {
+					walker.onError(err)
+				}
+				rules[path] = append(parentRules[:len(parentRules):len(parentRules)], dirRules...)
+			}
+		case entry.Type()&fs.ModeSymlink != 0:
+			// С -r символические ссылки внутри директорий пропускаются, с -R - разыменовываются
+			if !walker.options.Dereference {
+				return nil
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				walker.onError(displayError(err, show(path)))
+				return nil
+			}
+			if ignored(path, info.IsDir()) {
+				return nil
+			}
+			if info.IsDir() {
+				if !matchesAny(walker.options.ExcludeDir, path) {
+					walker.walk(path, show(path), rules[filepath.Dir(path)])
+				}
+			} else if info.Mode().IsRegular() && includedFile(path, walker.options) {
+				walker.stopped = !walker.visit(show(path))
+			}
+		case entry.Type().IsRegular():
+			if !ignored(path, false) && includedFile(path, walker.options) {
+				walker.stopped = !walker.visit(show(path))
+			}
+		}
+		return nil
+	})
+	return !walker.stopped
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
@@ -21,6 +23,26 @@ import (
 -v - "invert" (вместо совпадения, исключать)
 -F - "fixed", точное совпадение со строкой, не паттерн
 -n - "line num", печатать номер строки
+-r, -R - рекурсивный поиск по директориям (-R разыменовывает все символические ссылки)
+--include, --exclude, --exclude-dir - шаблоны имён файлов и директорий для поиска
+-H, -h - выводить (не выводить) имя файла перед строками
+-j - количество файлов, в которых поиск выполняется параллельно
+-P - шаблоны в стиле Perl (обратные ссылки, просмотр вперёд и назад), --perl-timeout - ограничение времени поиска в строке
+-e, -f - шаблоны из аргумента (можно повторять) или из файла, по одному в строке
+-o - печатать только совпавшие части строк
+-b - печатать смещение в байтах перед строками
+--color=never|always|auto - подсвечивать совпадения
+-w, -x - совпадение только с целыми словами (строками)
+-l, -L - печатать только имена файлов с совпадениями (без совпадений)
+-m - прекратить чтение файла после NUM найденных строк
+-q - не выводить ничего, код завершения 0 - есть совпадение, 1 - нет, 2 - ошибка
+--group-separator, --no-group-separator - разделитель групп строк контекста (по умолчанию "--")
+--json - выводить результат событиями JSON, по одному на строку (begin, match, context, end, summary)
+--field, --delimiter - искать совпадения только в поле N строки (поля разделены DELIM, по умолчанию табуляцией)
+--only-group - печатать только группу захвата с указанным именем или номером (как -o)
+--follow - следить за растущим файлом (как tail -F): выводить новые совпадения, переоткрывать файл при ротации и усечении
+-z - искать в сжатых данных (gzip, bzip2 и zstd определяются по сигнатуре в начале файла или стандартного ввода)
+--gitignore - при рекурсивном поиске пропускать файлы, игнорируемые .gitignore
 
 Программа должна проходить все тесты. Код должен проходить проверки go vet и golint.
 */
@@ -28,26 +50,17 @@ import (
 func main() {
 	options, err := grep.ParseArguments(os.Args[1:])
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return
-	}
-
-	if len(options.Filepaths) == 0 {
-		if err := grep.GREP(os.Stdin, os.Stdout, options); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, flag.ErrHelp) {
+			return
 		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(grep.ExitError)
 	}
 
-	for _, filepath := range options.Filepaths {
-		file, err := os.Open(filepath)
-		if err != nil {
-			fmt.Fprint(os.Stderr, err)
-			return
-		}
-		defer file.Close()
-		fmt.Println(filepath)
-		if err := grep.GREP(file, os.Stdout, options); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-		}
+	found, err := grep.Search(os.Stdin, os.Stdout, os.Stderr, options)
+	// Ошибки чтения отдельных файлов уже выведены при поиске
+	if err != nil && !errors.Is(err, grep.ErrFilesFailed) {
+		fmt.Fprintln(os.Stderr, err)
 	}
+	os.Exit(grep.ExitStatus(found, err, options.Quiet))
 }
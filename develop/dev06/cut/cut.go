@@ -3,7 +3,9 @@ package cut
 import (
 	"bufio"
 	"io"
+	"slices"
 	"strings"
+	"unicode/utf8"
 )
 
 func WriteWithDelimiter(writer *bufio.Writer, content, delimiter string) error {
@@ -16,6 +18,66 @@ func WriteWithDelimiter(writer *bufio.Writer, content, delimiter string) error {
 	return nil
 }
 
+// Номера позиций (от 0), выбранных параметрами, для строки из n позиций - в порядке возрастания
+// (байты и символы выводятся в том порядке, в котором они идут в строке)
+func SelectedIndexes(params FieldsParams, n int) []int {
+	selected := make([]bool, n)
+	for _, i := range params.Fields {
+		if i < n {
+			selected[i] = true
+		}
+	}
+	indexes := make([]int, 0, n)
+	for i := range selected {
+		if selected[i] || (params.IsFromStart && i <= params.FromStartTo) || (params.IsToEnd && i >= params.FromToEnd) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Вывод выбранных байтов (-b) или символов (-c) строки. Некорректные байты UTF-8 считаются отдельными
+// символами; с -b и -n многобайтовый символ выводится, только если выбраны все его байты (как в POSIX cut:
+// граница диапазона внутри символа не расширяет диапазон до символа целиком)
+func CutPositions(writer *bufio.Writer, str string, options Options) error {
+	var parts []string
+	switch {
+	case options.Mode == ModeChars:
+		parts = make([]string, 0, utf8.RuneCountInString(str))
+		for i := 0; i < len(str); {
+			_, size := utf8.DecodeRuneInString(str[i:])
+			parts = append(parts, str[i:i+size])
+			i += size
+		}
+	case options.NoSplit:
+		// Символ выводится на месте своего первого байта, остальные его байты не выводятся
+		selected := make([]bool, len(str))
+		for _, i := range SelectedIndexes(options.FieldsParams, len(str)) {
+			selected[i] = true
+		}
+		parts = make([]string, len(str))
+		for i := 0; i < len(str); {
+			_, size := utf8.DecodeRuneInString(str[i:])
+			if !slices.Contains(selected[i:i+size], false) {
+				parts[i] = str[i : i+size]
+			}
+			i += size
+		}
+	default:
+		parts = make([]string, len(str))
+		for i := range parts {
+			parts[i] = str[i : i+1]
+		}
+	}
+
+	for _, i := range SelectedIndexes(options.FieldsParams, len(parts)) {
+		if _, err := writer.WriteString(parts[i]); err != nil {
+			return err
+		}
+	}
+	return writer.WriteByte('\n')
+}
+
 // Реализация утилиты cut
 func Cut(in io.Reader, out io.Writer, options Options) error {
 	reader := bufio.NewReader(in)
@@ -40,6 +102,14 @@ func Cut(in io.Reader, out io.Writer, options Options) error {
 	}
 
 	for _, str := range text {
+		// Выбор байтов или символов (-b, -c) - без разбиения на поля
+		if options.Mode != ModeFields {
+			if err := CutPositions(writer, str, options); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Разбиение строки по разделителю
 		splitted := strings.Split(str, options.Delimiter)
 		n := len(splitted)
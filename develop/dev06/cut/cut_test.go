@@ -2,6 +2,7 @@ package cut
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -114,6 +115,36 @@ func TestParseArguments(t *testing.T) {
 			arguments:      []string{"-f", "1-5", "-d", " "},
 			expectedOutput: NewOptions(FieldsParams{Fields: []int{0, 1, 2, 3, 4}}, " ", false),
 			expectedError:  nil,
+		}, {
+			name:           "Bytes",
+			arguments:      []string{"-b", "1-3,5-", "-n"},
+			expectedOutput: Options{FieldsParams: FieldsParams{Fields: []int{0, 1, 2}, FromToEnd: 4, IsToEnd: true}, Delimiter: "\t", Mode: ModeBytes, NoSplit: true},
+			expectedError:  nil,
+		}, {
+			name:           "Characters",
+			arguments:      []string{"-c", "-2"},
+			expectedOutput: Options{FieldsParams: FieldsParams{FromStartTo: 1, IsFromStart: true}, Delimiter: "\t", Mode: ModeChars},
+			expectedError:  nil,
+		}, {
+			name:           "Several lists",
+			arguments:      []string{"-f", "1", "-c", "2"},
+			expectedOutput: Options{},
+			expectedError:  ErrOnlyOneList,
+		}, {
+			name:           "Delimiter with characters",
+			arguments:      []string{"-c", "2", "-d", ","},
+			expectedOutput: Options{},
+			expectedError:  ErrFieldsOnlyFlag,
+		}, {
+			name:           "Wrong bytes list",
+			arguments:      []string{"-b", "-"},
+			expectedOutput: Options{},
+			expectedError:  ErrWrongPositionEntry,
+		}, {
+			name:           "No split without bytes",
+			arguments:      []string{"-c", "1", "-n"},
+			expectedOutput: Options{},
+			expectedError:  ErrNoSplitWithoutBytes,
 		}, {
 			name:           "No fields flag",
 			arguments:      []string{"-d", " ", "-s"},
@@ -125,7 +156,7 @@ func TestParseArguments(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			got, err := ParseArguments(testCase.arguments)
 
-			if err != testCase.expectedError {
+			if !errors.Is(err, testCase.expectedError) {
 				t.Errorf("error: got %v, want %v", err, testCase.expectedError)
 			}
 
@@ -137,6 +168,14 @@ func TestParseArguments(t *testing.T) {
 				t.Errorf("result: Separated got %v, want %v", got.Separated, testCase.expectedOutput.Separated)
 			}
 
+			if got.Mode != testCase.expectedOutput.Mode {
+				t.Errorf("result: Mode got %v, want %v", got.Mode, testCase.expectedOutput.Mode)
+			}
+
+			if got.NoSplit != testCase.expectedOutput.NoSplit {
+				t.Errorf("result: NoSplit got %v, want %v", got.NoSplit, testCase.expectedOutput.NoSplit)
+			}
+
 			CompareFieldsParams(t, got.FieldsParams, testCase.expectedOutput.FieldsParams)
 		})
 	}
@@ -196,3 +235,57 @@ func TestCut(t *testing.T) {
 		})
 	}
 }
+
+func TestCutPositions(t *testing.T) {
+	const inputText = "abcdef\nпривет\nab\xffcd\n"
+	testCases := []struct {
+		name           string
+		arguments      []string
+		expectedOutput string
+	}{
+		{
+			name:           "Bytes",
+			arguments:      []string{"-b", "1,3-4"},
+			expectedOutput: "acd\n\xd0\xd1\x80\na\xffc\n",
+		}, {
+			name:           "Bytes from start and to end",
+			arguments:      []string{"-b", "-2,5-"},
+			expectedOutput: "abef\n\xd0\xbf\xd0\xb8\xd0\xb2\xd0\xb5\xd1\x82\nabd\n",
+		}, {
+			name:           "Bytes without splitting characters",
+			arguments:      []string{"-b", "1-3", "-n"},
+			expectedOutput: "abc\nп\nab\xff\n",
+		}, {
+			name:           "Characters",
+			arguments:      []string{"-c", "2,4-"},
+			expectedOutput: "bdef\nрвет\nbcd\n",
+		}, {
+			name:           "Characters in input order",
+			arguments:      []string{"-c", "5,1"},
+			expectedOutput: "ae\nпе\nad\n",
+		}, {
+			name:           "Bytes in input order",
+			arguments:      []string{"-b", "4,2,1", "-n"},
+			expectedOutput: "abd\nп\nabc\n",
+		}, {
+			name:           "Range splitting characters at both ends",
+			arguments:      []string{"-b", "2-5", "-n"},
+			expectedOutput: "bcde\nр\nb\xffcd\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			options, err := ParseArguments(testCase.arguments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buffer bytes.Buffer
+			if err := Cut(strings.NewReader(inputText), &buffer, options); err != nil {
+				t.Errorf("error: got %s, want %v", err, nil)
+			}
+			if got := buffer.String(); got != testCase.expectedOutput {
+				t.Errorf("result: got %q, want %q", got, testCase.expectedOutput)
+			}
+		})
+	}
+}
@@ -3,12 +3,24 @@ package cut
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
 var ErrParsingError error = errors.New("an error occurred while parsing flags")
 var ErrWrongFieldEntry error = errors.New("-f must me string with positive numbers like `1,2,3`, `1-3`, `-3`, `5-`, or combined `1,3-4,6,10-`")
+var ErrWrongPositionEntry error = errors.New("must be string with positive numbers like `1,2,3`, `1-3`, `-3`, `5-`, or combined `1,3-4,6,10-`")
+var ErrNoSplitWithoutBytes error = errors.New("-n may be specified only with -b")
+var ErrOnlyOneList error = errors.New("only one of -f, -b and -c may be specified")
+var ErrFieldsOnlyFlag error = errors.New("-d and -s may be specified only with -f")
+
+// Режимы выбора: поля (-f), байты (-b) или символы UTF-8 (-c)
+const (
+	ModeFields = iota
+	ModeBytes
+	ModeChars
+)
 
 type FieldsParams struct {
 	Fields      []int
@@ -106,6 +118,8 @@ type Options struct {
 	FieldsParams
 	Delimiter string
 	Separated bool
+	Mode      int
+	NoSplit   bool
 }
 
 func NewOptions(fieldsParams FieldsParams, delimiter string, separated bool) Options {
@@ -120,15 +134,54 @@ func NewOptions(fieldsParams FieldsParams, delimiter string, separated bool) Opt
 func ParseArguments(arguments []string) (Options, error) {
 	fSet := flag.NewFlagSet("cut", flag.ContinueOnError)
 	fieldsStr := fSet.String("f", "", "fields for output")
+	bytesStr := fSet.String("b", "", "bytes for output")
+	charsStr := fSet.String("c", "", "characters for output")
 	delimiter := fSet.String("d", "\t", "delimiter for input")
 	separated := fSet.Bool("s", false, "output only separated input")
+	noSplit := fSet.Bool("n", false, "with -b: do not split multibyte characters")
 	if fSet.Parse(arguments) != nil {
 		return Options{}, ErrParsingError
 	}
-	// Приведение флага -f к структуре типа FieldsParams
-	fields, err := GetFieldsFromString(*fieldsStr)
-	if err != nil {
+
+	// Определение режима по указанным спискам (без списков - ошибка разбора пустого списка -f)
+	listStr, mode, lists := *fieldsStr, ModeFields, 0
+	fieldsOnly := false
+	fSet.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "f":
+			lists++
+		case "b":
+			listStr, mode = *bytesStr, ModeBytes
+			lists++
+		case "c":
+			listStr, mode = *charsStr, ModeChars
+			lists++
+		case "d", "s":
+			fieldsOnly = true
+		}
+	})
+	if lists > 1 {
+		return Options{}, ErrOnlyOneList
+	}
+	if fieldsOnly && mode != ModeFields {
+		return Options{}, ErrFieldsOnlyFlag
+	}
+	if *noSplit && mode != ModeBytes {
+		return Options{}, ErrNoSplitWithoutBytes
+	}
+
+	// Приведение списка к структуре типа FieldsParams (для -b и -c позиции задаются так же, как поля)
+	fields, err := GetFieldsFromString(listStr)
+	switch {
+	case err != nil && mode == ModeBytes:
+		return Options{}, fmt.Errorf("-b %w", ErrWrongPositionEntry)
+	case err != nil && mode == ModeChars:
+		return Options{}, fmt.Errorf("-c %w", ErrWrongPositionEntry)
+	case err != nil:
 		return Options{}, err
 	}
-	return NewOptions(fields, *delimiter, *separated), nil
+	options := NewOptions(fields, *delimiter, *separated)
+	options.Mode = mode
+	options.NoSplit = *noSplit
+	return options, nil
 }
@@ -16,6 +16,9 @@ import (
 -f - "fields" - выбрать поля (колонки)
 -d - "delimiter" - использовать другой разделитель
 -s - "separated" - только строки с разделителем
+-b - "bytes" - выбрать байты (список задаётся так же, как для -f)
+-c - "characters" - выбрать символы UTF-8
+-n - с -b не разбивать многобайтовые символы
 
 Программа должна проходить все тесты. Код должен проходить проверки go vet и golint.
 */